@@ -0,0 +1,197 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package pwsh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+	"github.com/zclconf/go-cty/cty"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	pwshDatasourceExecutingErrorFormat = "Error executing PowerShell data source: %s."
+	pwshDatasourceParsingErrorFormat   = "Error parsing PowerShell data source output as JSON: %s."
+	pwshDatasourcePreparingErrorFormat = "Error preparing PowerShell data source: %s."
+)
+
+type Config struct {
+	Env            map[string]string `mapstructure:"env"`
+	ExtraArguments []string          `mapstructure:"extra_arguments"`
+	Inline         []string          `mapstructure:"inline"`
+	PwshPath       string            `mapstructure:"pwsh_path"`
+	Script         string            `mapstructure:"script"`
+	Timeout        time.Duration     `mapstructure:"timeout"`
+}
+type Datasource struct {
+	config Config
+}
+
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec { return d.config.FlatMapstructure().HCL2Spec() }
+func (d *Datasource) Configure(raws ...interface{}) error {
+	if e := config.Decode(
+		&d.config,
+		&config.DecodeOpts{
+			DecodeHooks: config.DefaultDecodeHookFuncs,
+			PluginType:  "pwsh",
+		},
+		raws...,
+	); nil != e {
+		return e
+	} else {
+		if "" == d.config.PwshPath {
+			d.config.PwshPath = "pwsh"
+		}
+
+		for name := range d.config.Env {
+			if ("" == name) || strings.ContainsAny(name, "=\x00") {
+				e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'env' name is not a valid environment variable name: %s.", name))
+			}
+		}
+
+		if 0 > d.config.Timeout {
+			e = packersdk.MultiErrorAppend(e, errors.New("The 'timeout' parameter must not be negative."))
+		}
+
+		if (0 == len(d.config.Inline)) && ("" == d.config.Script) {
+			e = packersdk.MultiErrorAppend(e, errors.New("Either a script file or an inline script must be specified."))
+		} else if (0 < len(d.config.Inline)) && ("" != d.config.Script) {
+			e = packersdk.MultiErrorAppend(e, errors.New("Only a script file or an inline script can be specified, not both."))
+		} else if "" != d.config.Script {
+			if _, err := os.Stat(d.config.Script); nil != err {
+				e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'script' file could not be read: %s.", err))
+			}
+		}
+
+		if nil != e {
+			return e
+		}
+
+		return nil
+	}
+}
+func (d *Datasource) Execute() (cty.Value, error) {
+	nullValue := cty.NullVal(hcldec.ImpliedType(d.OutputSpec()))
+
+	if output, e := d.executeScript(); nil != e {
+		return nullValue, e
+	} else if "" == output {
+		return cty.ObjectVal(map[string]cty.Value{
+			"output": cty.StringVal(output),
+			"result": cty.NullVal(cty.DynamicPseudoType),
+		}), nil
+	} else if resultType, e := ctyjson.ImpliedType([]byte(output)); nil != e {
+		return nullValue, fmt.Errorf(pwshDatasourceParsingErrorFormat, e)
+	} else if result, e := ctyjson.Unmarshal([]byte(output), resultType); nil != e {
+		return nullValue, fmt.Errorf(pwshDatasourceParsingErrorFormat, e)
+	} else {
+		return cty.ObjectVal(map[string]cty.Value{
+			"output": cty.StringVal(output),
+			"result": result,
+		}), nil
+	}
+}
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return hcldec.ObjectSpec{
+		"output": &hcldec.AttrSpec{Name: "output", Type: cty.String},
+		"result": &hcldec.AttrSpec{Name: "result", Type: cty.DynamicPseudoType},
+	}
+}
+
+func (d *Datasource) executeScript() (string, error) {
+	scriptPath := d.config.Script
+
+	if 0 < len(d.config.Inline) {
+		if inlineScriptPath, e := d.getInlineScriptFilePath(); nil != e {
+			return "", e
+		} else {
+			defer os.Remove(inlineScriptPath)
+
+			scriptPath = inlineScriptPath
+		}
+	}
+
+	ctx := context.Background()
+
+	if 0 < d.config.Timeout {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, d.config.Timeout)
+
+		defer cancel()
+	}
+
+	var stderr bytes.Buffer
+	var stdout bytes.Buffer
+
+	arguments := append([]string{"-NoLogo", "-NonInteractive", "-NoProfile", "-ExecutionPolicy", "Bypass"}, d.config.ExtraArguments...)
+	cmd := exec.CommandContext(ctx, d.config.PwshPath, append(arguments, "-File", scriptPath)...)
+	cmd.Env = os.Environ()
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stdout
+
+	names := make([]string, 0, len(d.config.Env))
+
+	for name := range d.config.Env {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmd.Env = append(cmd.Env, (name + "=" + d.config.Env[name]))
+	}
+
+	if e := cmd.Run(); nil != e {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf(pwshDatasourceExecutingErrorFormat, fmt.Sprintf("timed out after %s", d.config.Timeout))
+		} else if message := strings.TrimSpace(stderr.String()); "" != message {
+			return "", fmt.Errorf(pwshDatasourceExecutingErrorFormat, fmt.Sprintf("%s; %s", e, message))
+		}
+
+		return "", fmt.Errorf(pwshDatasourceExecutingErrorFormat, e)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+func (d *Datasource) getInlineScriptFilePath() (string, error) {
+	if scriptFileHandle, e := tmp.File("pwsh-datasource"); nil != e {
+		return "", fmt.Errorf(pwshDatasourcePreparingErrorFormat, e)
+	} else {
+		writer := bufio.NewWriter(scriptFileHandle)
+
+		for _, line := range d.config.Inline {
+			if _, e := writer.WriteString(line + "\n"); nil != e {
+				scriptFileHandle.Close()
+
+				return "", fmt.Errorf(pwshDatasourcePreparingErrorFormat, e)
+			}
+		}
+
+		if e = writer.Flush(); nil != e {
+			scriptFileHandle.Close()
+
+			return "", fmt.Errorf(pwshDatasourcePreparingErrorFormat, e)
+		} else if e = scriptFileHandle.Close(); nil != e {
+			return "", fmt.Errorf(pwshDatasourcePreparingErrorFormat, e)
+		} else if e = os.Rename(scriptFileHandle.Name(), (scriptFileHandle.Name() + ".ps1")); nil != e {
+			return "", fmt.Errorf(pwshDatasourcePreparingErrorFormat, e)
+		}
+
+		return (scriptFileHandle.Name() + ".ps1"), nil
+	}
+}
@@ -0,0 +1,41 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package pwsh
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatConfig struct {
+	Env            map[string]string `mapstructure:"env" cty:"env" hcl:"env"`
+	ExtraArguments []string          `mapstructure:"extra_arguments" cty:"extra_arguments" hcl:"extra_arguments"`
+	Inline         []string          `mapstructure:"inline" cty:"inline" hcl:"inline"`
+	PwshPath       *string           `mapstructure:"pwsh_path" cty:"pwsh_path" hcl:"pwsh_path"`
+	Script         *string           `mapstructure:"script" cty:"script" hcl:"script"`
+	Timeout        *string           `mapstructure:"timeout" cty:"timeout" hcl:"timeout"`
+}
+
+// FlatMapstructure returns a new FlatConfig.
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatConfig)
+}
+
+// HCL2Spec returns the hcl spec of a Config.
+// This spec is used by HCL to read the fields of Config.
+// The decoded values from this spec will then be applied to a FlatConfig.
+func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"env":             &hcldec.AttrSpec{Name: "env", Type: cty.Map(cty.String), Required: false},
+		"extra_arguments": &hcldec.AttrSpec{Name: "extra_arguments", Type: cty.List(cty.String), Required: false},
+		"inline":          &hcldec.AttrSpec{Name: "inline", Type: cty.List(cty.String), Required: false},
+		"pwsh_path":       &hcldec.AttrSpec{Name: "pwsh_path", Type: cty.String, Required: false},
+		"script":          &hcldec.AttrSpec{Name: "script", Type: cty.String, Required: false},
+		"timeout":         &hcldec.AttrSpec{Name: "timeout", Type: cty.String, Required: false},
+	}
+	return s
+}
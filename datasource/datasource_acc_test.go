@@ -0,0 +1,39 @@
+package pwsh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	_ "embed"
+
+	"github.com/hashicorp/packer-plugin-sdk/acctest"
+)
+
+//go:embed test-fixtures/datasource.pkr.hcl
+var testAccDatasourceTemplate string
+
+func TestAccDatasource(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check: func(buildCommand *exec.Cmd, logfile string) error {
+			if nil == buildCommand.ProcessState {
+				return fmt.Errorf("packer did not run; logfile: %s", logfile)
+			} else if exitCode := buildCommand.ProcessState.ExitCode(); 0 != exitCode {
+				return fmt.Errorf("expected packer to exit with 0, got %d; logfile: %s", exitCode, logfile)
+			}
+
+			if logs, e := os.ReadFile(logfile); nil != e {
+				return fmt.Errorf("unable to read %s: %s", logfile, e)
+			} else if !regexp.MustCompile(`packer-acc-datasource:1\.2\.3\|2`).Match(logs) {
+				return fmt.Errorf("expected the logs to contain the data source result; logfile: %s", logfile)
+			}
+
+			return nil
+		},
+		Name:     "pwsh_datasource_test",
+		Template: testAccDatasourceTemplate,
+		Type:     "pwsh",
+	})
+}
@@ -0,0 +1,95 @@
+package pwsh
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testPwshPath(t *testing.T) string {
+	if "windows" == runtime.GOOS {
+		t.Skip("the stand-in pwsh executable is a POSIX shell script")
+	}
+
+	pwshPath := filepath.Join(t.TempDir(), "pwsh")
+
+	// Stands in for pwsh by running the script passed to -File with sh.
+	if e := os.WriteFile(pwshPath, []byte("#!/bin/sh\nfor argument; do scriptPath=\"$argument\"; done\nexec sh \"$scriptPath\"\n"), 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	return pwshPath
+}
+
+func TestDatasource_Configure(t *testing.T) {
+	testCases := []struct {
+		config          map[string]interface{}
+		isErrorExpected bool
+	}{
+		{map[string]interface{}{"inline": []string{"@{} | ConvertTo-Json"}}, false},
+		{map[string]interface{}{}, true},
+		{map[string]interface{}{"inline": []string{"@{} | ConvertTo-Json"}, "script": "version.ps1"}, true},
+		{map[string]interface{}{"script": filepath.Join("test-fixtures", "missing.ps1")}, true},
+		{map[string]interface{}{"inline": []string{"@{} | ConvertTo-Json"}, "timeout": "-1s"}, true},
+	}
+
+	for _, testCase := range testCases {
+		if e := new(Datasource).Configure(testCase.config); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%v: expected error: %t, got %v", testCase.config, testCase.isErrorExpected, e)
+		}
+	}
+}
+func TestDatasource_Execute(t *testing.T) {
+	d := new(Datasource)
+
+	if e := d.Configure(map[string]interface{}{
+		"env":       map[string]string{"IMAGE_VERSION": "1.2.3"},
+		"inline":    []string{`echo "{\"Tags\":[\"a\",\"b\"],\"Version\":\"$IMAGE_VERSION\"}"`},
+		"pwsh_path": testPwshPath(t),
+	}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	value, e := d.Execute()
+
+	if nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	result := value.GetAttr("result")
+
+	if version := result.GetAttr("Version"); !version.RawEquals(cty.StringVal("1.2.3")) {
+		t.Errorf("expected Version to be 1.2.3, got %#v", version)
+	}
+
+	if tags := result.GetAttr("Tags"); 2 != tags.LengthInt() {
+		t.Errorf("expected two tags, got %#v", tags)
+	}
+}
+func TestDatasource_ExecuteFailure(t *testing.T) {
+	testCases := []struct {
+		name   string
+		inline []string
+	}{
+		{"exit code", []string{"echo 'Boom' >&2", "exit 1"}},
+		{"invalid json", []string{"echo 'not json'"}},
+	}
+
+	for _, testCase := range testCases {
+		d := new(Datasource)
+
+		if e := d.Configure(map[string]interface{}{
+			"inline":    testCase.inline,
+			"pwsh_path": testPwshPath(t),
+		}); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.name, e)
+		}
+
+		if _, e := d.Execute(); nil == e {
+			t.Errorf("%s: expected an error", testCase.name)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+data "pwsh" "version" {
+  inline = [
+    "[ordered]@{ Tags = @('a', 'b'); Version = '1.2.3'; } | ConvertTo-Json -Compress;",
+  ]
+}
+
+source "null" "pwsh" {
+  communicator = "none"
+}
+
+build {
+  sources = ["source.null.pwsh"]
+
+  provisioner "pwsh" {
+    execution_target = "local"
+    inline = [
+      "Write-Output 'packer-acc-datasource:${data.pwsh.version.result.Version}|${length(data.pwsh.version.result.Tags)}';",
+    ]
+  }
+}
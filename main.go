@@ -7,13 +7,19 @@ import (
 	"github.com/ByteTerrace/packer-plugin-pwsh/version"
 	"github.com/hashicorp/packer-plugin-sdk/plugin"
 
-	pwsh "github.com/ByteTerrace/packer-plugin-pwsh/provisioner"
+	pwshdatasource "github.com/ByteTerrace/packer-plugin-pwsh/datasource"
+	pwshpostprocessor "github.com/ByteTerrace/packer-plugin-pwsh/postprocessor"
+	pwshprovisioner "github.com/ByteTerrace/packer-plugin-pwsh/provisioner"
 )
 
 func main() {
 	pluginSet := plugin.NewSet()
 
-	pluginSet.RegisterProvisioner(plugin.DEFAULT_NAME, new(pwsh.Provisioner))
+	pluginSet.RegisterDatasource(plugin.DEFAULT_NAME, new(pwshdatasource.Datasource))
+	pluginSet.RegisterPostProcessor(plugin.DEFAULT_NAME, new(pwshpostprocessor.PostProcessor))
+	pluginSet.RegisterProvisioner(plugin.DEFAULT_NAME, new(pwshprovisioner.Provisioner))
+	pluginSet.RegisterProvisioner("dsc", new(pwshprovisioner.DscProvisioner))
+	pluginSet.RegisterProvisioner("pester", new(pwshprovisioner.PesterProvisioner))
 	pluginSet.SetVersion(version.PluginVersion)
 
 	e := pluginSet.Run()
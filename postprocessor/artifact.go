@@ -0,0 +1,37 @@
+package pwsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+const BuilderId = "byteterrace.post-processor.pwsh"
+
+type Artifact struct {
+	files []string
+	id    string
+	state map[string]interface{}
+}
+
+func (a *Artifact) BuilderId() string {
+	return BuilderId
+}
+func (a *Artifact) Destroy() error {
+	return nil
+}
+func (a *Artifact) Files() []string {
+	return a.files
+}
+func (a *Artifact) Id() string {
+	return a.id
+}
+func (a *Artifact) State(name string) interface{} {
+	return a.state[name]
+}
+func (a *Artifact) String() string {
+	if 0 == len(a.files) {
+		return fmt.Sprintf("PowerShell post-processor artifact: %s", a.id)
+	}
+
+	return fmt.Sprintf("PowerShell post-processor artifact: %s; files: %s", a.id, strings.Join(a.files, ", "))
+}
@@ -0,0 +1,251 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package pwsh
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+const (
+	pwshArtifactPrefix                    = "packer-artifact:"
+	pwshPostProcessorExecutingErrorFormat = "Error executing PowerShell post-processor: %s."
+	pwshPostProcessorParsingErrorFormat   = "Error parsing PowerShell post-processor artifact: %s."
+	pwshPostProcessorPreparingErrorFormat = "Error preparing PowerShell post-processor: %s."
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	Env            map[string]string `mapstructure:"env"`
+	ExtraArguments []string          `mapstructure:"extra_arguments"`
+	Inline         []string          `mapstructure:"inline"`
+	PwshPath       string            `mapstructure:"pwsh_path"`
+	Script         string            `mapstructure:"script"`
+
+	ctx interpolate.Context
+}
+type PostProcessor struct {
+	config Config
+}
+
+type artifactRecord struct {
+	Files *[]string `json:"Files"`
+	Id    *string   `json:"Id"`
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	if e := config.Decode(
+		&p.config,
+		&config.DecodeOpts{
+			DecodeHooks:        config.DefaultDecodeHookFuncs,
+			Interpolate:        true,
+			InterpolateContext: &p.config.ctx,
+			PluginType:         "pwsh",
+		},
+		raws...,
+	); nil != e {
+		return e
+	} else {
+		if "" == p.config.PwshPath {
+			p.config.PwshPath = "pwsh"
+		}
+
+		for name := range p.config.Env {
+			if ("" == name) || strings.ContainsAny(name, "=\x00") {
+				e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'env' name is not a valid environment variable name: %s.", name))
+			}
+		}
+
+		if (0 == len(p.config.Inline)) && ("" == p.config.Script) {
+			e = packersdk.MultiErrorAppend(e, errors.New("Either a script file or an inline script must be specified."))
+		} else if (0 < len(p.config.Inline)) && ("" != p.config.Script) {
+			e = packersdk.MultiErrorAppend(e, errors.New("Only a script file or an inline script can be specified, not both."))
+		} else if "" != p.config.Script {
+			if _, err := os.Stat(p.config.Script); nil != err {
+				e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'script' file could not be read: %s.", err))
+			}
+		}
+
+		if nil != e {
+			return e
+		}
+
+		return nil
+	}
+}
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, artifact packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	ui.Say(fmt.Sprintf("Running PowerShell post-processor; artifact: %s", artifact.Id()))
+
+	if environment, e := p.getEnvironment(artifact); nil != e {
+		return nil, false, false, e
+	} else if lines, e := p.executeScript(ctx, environment, ui); nil != e {
+		return nil, false, false, e
+	} else if 0 == len(lines) {
+		return artifact, true, false, nil
+	} else {
+		record := artifactRecord{}
+
+		if e = json.Unmarshal([]byte(lines[len(lines)-1]), &record); nil != e {
+			return nil, false, false, fmt.Errorf(pwshPostProcessorParsingErrorFormat, e)
+		}
+
+		modifiedArtifact := &Artifact{
+			files: artifact.Files(),
+			id:    artifact.Id(),
+			state: map[string]interface{}{"generated_data": artifact.State("generated_data")},
+		}
+
+		if nil != record.Files {
+			modifiedArtifact.files = *record.Files
+		}
+
+		if nil != record.Id {
+			modifiedArtifact.id = *record.Id
+		}
+
+		ui.Say(fmt.Sprintf("PowerShell post-processor returned a modified artifact: %s", modifiedArtifact.id))
+
+		// The input artifact is kept unless the core 'keep_input_artifact' option says otherwise.
+		return modifiedArtifact, true, false, nil
+	}
+}
+
+func (p *PostProcessor) executeScript(ctx context.Context, environment []string, ui packersdk.Ui) ([]string, error) {
+	scriptPath := p.config.Script
+
+	if 0 < len(p.config.Inline) {
+		if inlineScriptPath, e := p.getInlineScriptFilePath(); nil != e {
+			return nil, e
+		} else {
+			defer os.Remove(inlineScriptPath)
+
+			scriptPath = inlineScriptPath
+		}
+	}
+
+	arguments := append([]string{"-NoLogo", "-NonInteractive", "-NoProfile", "-ExecutionPolicy", "Bypass"}, p.config.ExtraArguments...)
+	artifactLines := make([]string, 0)
+	cmd := exec.CommandContext(ctx, p.config.PwshPath, append(arguments, "-File", scriptPath)...)
+	cmd.Env = append(os.Environ(), environment...)
+
+	var waitGroup sync.WaitGroup
+
+	forwardLines := func(reader io.Reader, write func(string)) {
+		defer waitGroup.Done()
+
+		scanner := bufio.NewScanner(reader)
+
+		for scanner.Scan() {
+			write(scanner.Text())
+		}
+	}
+
+	if stderr, e := cmd.StderrPipe(); nil != e {
+		return nil, fmt.Errorf(pwshPostProcessorExecutingErrorFormat, e)
+	} else if stdout, e := cmd.StdoutPipe(); nil != e {
+		return nil, fmt.Errorf(pwshPostProcessorExecutingErrorFormat, e)
+	} else if e = cmd.Start(); nil != e {
+		return nil, fmt.Errorf(pwshPostProcessorExecutingErrorFormat, e)
+	} else {
+		waitGroup.Add(2)
+
+		go forwardLines(stderr, ui.Error)
+		go forwardLines(stdout, func(line string) {
+			if strings.HasPrefix(line, pwshArtifactPrefix) {
+				artifactLines = append(artifactLines, strings.TrimPrefix(line, pwshArtifactPrefix))
+			} else {
+				ui.Message(line)
+			}
+		})
+
+		waitGroup.Wait()
+
+		if e = cmd.Wait(); nil != e {
+			return nil, fmt.Errorf(pwshPostProcessorExecutingErrorFormat, e)
+		}
+
+		return artifactLines, nil
+	}
+}
+func (p *PostProcessor) getEnvironment(artifact packersdk.Artifact) ([]string, error) {
+	files := artifact.Files()
+
+	if nil == files {
+		files = make([]string, 0)
+	}
+
+	if filesJson, e := json.Marshal(files); nil != e {
+		return nil, fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+	} else if stateJson, e := json.Marshal(artifact.State("generated_data")); nil != e {
+		return nil, fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+	} else {
+		environment := []string{
+			("PACKER_ARTIFACT_BUILDER_ID=" + artifact.BuilderId()),
+			("PACKER_ARTIFACT_FILES=" + string(filesJson)),
+			("PACKER_ARTIFACT_ID=" + artifact.Id()),
+			("PACKER_ARTIFACT_STATE=" + string(stateJson)),
+			("PACKER_ARTIFACT_STRING=" + artifact.String()),
+			("PACKER_BUILD_NAME=" + p.config.PackerBuildName),
+			("PACKER_BUILDER_TYPE=" + p.config.PackerBuilderType),
+		}
+		names := make([]string, 0, len(p.config.Env))
+
+		for name := range p.config.Env {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			environment = append(environment, (name + "=" + p.config.Env[name]))
+		}
+
+		return environment, nil
+	}
+}
+func (p *PostProcessor) getInlineScriptFilePath() (string, error) {
+	if scriptFileHandle, e := tmp.File("pwsh-post-processor"); nil != e {
+		return "", fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+	} else {
+		writer := bufio.NewWriter(scriptFileHandle)
+
+		for _, line := range p.config.Inline {
+			if _, e := writer.WriteString(line + "\n"); nil != e {
+				scriptFileHandle.Close()
+
+				return "", fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+			}
+		}
+
+		if e = writer.Flush(); nil != e {
+			scriptFileHandle.Close()
+
+			return "", fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+		} else if e = scriptFileHandle.Close(); nil != e {
+			return "", fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+		} else if e = os.Rename(scriptFileHandle.Name(), (scriptFileHandle.Name() + ".ps1")); nil != e {
+			return "", fmt.Errorf(pwshPostProcessorPreparingErrorFormat, e)
+		}
+
+		return (scriptFileHandle.Name() + ".ps1"), nil
+	}
+}
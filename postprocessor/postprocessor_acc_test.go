@@ -0,0 +1,43 @@
+package pwsh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	_ "embed"
+
+	"github.com/hashicorp/packer-plugin-sdk/acctest"
+)
+
+//go:embed test-fixtures/postprocessor.pkr.hcl
+var testAccPostProcessorTemplate string
+
+func TestAccPostProcessor(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check: func(buildCommand *exec.Cmd, logfile string) error {
+			if nil == buildCommand.ProcessState {
+				return fmt.Errorf("packer did not run; logfile: %s", logfile)
+			} else if exitCode := buildCommand.ProcessState.ExitCode(); 0 != exitCode {
+				return fmt.Errorf("expected packer to exit with 0, got %d; logfile: %s", exitCode, logfile)
+			}
+
+			if logs, e := os.ReadFile(logfile); nil != e {
+				return fmt.Errorf("unable to read %s: %s", logfile, e)
+			} else {
+				for _, pattern := range []string{`packer-acc-post-processor:pwsh`, `PowerShell post-processor artifact: pwsh-acc`} {
+					if !regexp.MustCompile(pattern).Match(logs) {
+						return fmt.Errorf("expected the logs to match %q; logfile: %s", pattern, logfile)
+					}
+				}
+			}
+
+			return nil
+		},
+		Name:     "pwsh_post_processor_test",
+		Template: testAccPostProcessorTemplate,
+		Type:     "pwsh",
+	})
+}
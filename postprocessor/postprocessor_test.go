@@ -0,0 +1,133 @@
+package pwsh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testArtifact() *packersdk.MockArtifact {
+	return &packersdk.MockArtifact{
+		BuilderIdValue: "packer.null",
+		FilesValue:     []string{"disk.vhdx"},
+		IdValue:        "image-1",
+		StateValues:    map[string]interface{}{"generated_data": map[string]interface{}{"ImageVersion": "1.2.3"}},
+		StringValue:    "image-1",
+	}
+}
+func testPwshPath(t *testing.T) string {
+	if "windows" == runtime.GOOS {
+		t.Skip("the stand-in pwsh executable is a POSIX shell script")
+	}
+
+	pwshPath := filepath.Join(t.TempDir(), "pwsh")
+
+	// Stands in for pwsh by running the script passed to -File with sh.
+	if e := os.WriteFile(pwshPath, []byte("#!/bin/sh\nfor argument; do scriptPath=\"$argument\"; done\nexec sh \"$scriptPath\"\n"), 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	return pwshPath
+}
+
+func TestPostProcessor_Configure(t *testing.T) {
+	testCases := []struct {
+		config          map[string]interface{}
+		isErrorExpected bool
+	}{
+		{map[string]interface{}{"inline": []string{"Write-Output $env:PACKER_ARTIFACT_ID;"}}, false},
+		{map[string]interface{}{}, true},
+		{map[string]interface{}{"inline": []string{"Write-Output $env:PACKER_ARTIFACT_ID;"}, "script": "register.ps1"}, true},
+		{map[string]interface{}{"script": filepath.Join("test-fixtures", "missing.ps1")}, true},
+	}
+
+	for _, testCase := range testCases {
+		if e := new(PostProcessor).Configure(testCase.config); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%v: expected error: %t, got %v", testCase.config, testCase.isErrorExpected, e)
+		}
+	}
+}
+func TestPostProcessor_PostProcess(t *testing.T) {
+	testCases := []struct {
+		name            string
+		inline          []string
+		isErrorExpected bool
+		expectedFiles   []string
+		expectedId      string
+		isModified      bool
+	}{
+		{
+			name:          "unchanged",
+			inline:        []string{`test "$PACKER_ARTIFACT_ID|$PACKER_ARTIFACT_FILES|$PACKER_ARTIFACT_STATE" = 'image-1|["disk.vhdx"]|{"ImageVersion":"1.2.3"}'`},
+			expectedFiles: []string{"disk.vhdx"},
+			expectedId:    "image-1",
+		},
+		{
+			name:          "modified",
+			inline:        []string{`echo 'registered'`, `echo 'packer-artifact:{"Id":"image-2"}'`},
+			expectedFiles: []string{"disk.vhdx"},
+			expectedId:    "image-2",
+			isModified:    true,
+		},
+		{
+			name:          "modified with files",
+			inline:        []string{`echo 'packer-artifact:{"Files":["manifest.json"]}'`},
+			expectedFiles: []string{"manifest.json"},
+			expectedId:    "image-1",
+			isModified:    true,
+		},
+		{
+			name:            "failure",
+			inline:          []string{"exit 4"},
+			isErrorExpected: true,
+		},
+		{
+			name:            "malformed artifact",
+			inline:          []string{`echo 'packer-artifact:{'`},
+			isErrorExpected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := new(PostProcessor)
+
+			if e := p.Configure(map[string]interface{}{
+				"inline":    testCase.inline,
+				"pwsh_path": testPwshPath(t),
+			}); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			artifact, keep, _, e := p.PostProcess(context.Background(), packersdk.TestUi(t), testArtifact())
+
+			if testCase.isErrorExpected != (nil != e) {
+				t.Fatalf("expected error: %t, got %v", testCase.isErrorExpected, e)
+			} else if nil != e {
+				return
+			}
+
+			if testCase.expectedId != artifact.Id() {
+				t.Errorf("expected id %q, got %q", testCase.expectedId, artifact.Id())
+			}
+
+			if (len(testCase.expectedFiles) != len(artifact.Files())) || (testCase.expectedFiles[0] != artifact.Files()[0]) {
+				t.Errorf("expected files %v, got %v", testCase.expectedFiles, artifact.Files())
+			}
+
+			if !keep {
+				t.Error("expected the input artifact to be kept unless the core option overrides it")
+			}
+
+			if isModified := (BuilderId == artifact.BuilderId()); testCase.isModified != isModified {
+				t.Errorf("expected a modified artifact: %t", testCase.isModified)
+			} else if isModified && (nil == artifact.State("generated_data")) {
+				t.Error("expected the generated data to be carried over")
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+source "null" "pwsh" {
+  communicator = "none"
+}
+
+build {
+  sources = ["source.null.pwsh"]
+
+  post-processor "pwsh" {
+    inline = [
+      "Write-Output ('packer-acc-post-processor:{0}' -f $env:PACKER_BUILD_NAME);",
+      "Write-Output 'packer-artifact:{\"Id\":\"pwsh-acc\"}';",
+    ]
+  }
+}
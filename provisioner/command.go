@@ -0,0 +1,129 @@
+package pwsh
+
+import (
+	"strings"
+)
+
+func isActionPreference(actionPreference string) bool {
+	for _, knownActionPreference := range []string{"Break", "Continue", "Ignore", "Inquire", "SilentlyContinue", "Stop", "Suspend"} {
+		if strings.EqualFold(knownActionPreference, actionPreference) {
+			return true
+		}
+	}
+
+	return false
+}
+func isExecutionPolicy(executionPolicy string) bool {
+	for _, knownExecutionPolicy := range []string{"AllSigned", "Bypass", "Default", "RemoteSigned", "Restricted", "Undefined", "Unrestricted"} {
+		if strings.EqualFold(knownExecutionPolicy, executionPolicy) {
+			return true
+		}
+	}
+
+	return false
+}
+func quoteLinuxArgument(argument string) string {
+	if ("" != argument) && !strings.ContainsAny(argument, " \t\n\"'\\$`&|;<>()*?[]#~{}!") {
+		return argument
+	}
+
+	return ("'" + strings.ReplaceAll(argument, "'", `'\''`) + "'")
+}
+func quoteWindowsArgument(argument string) string {
+	if ("" != argument) && !strings.ContainsAny(argument, " \t\"&|<>()^%") {
+		return argument
+	}
+
+	return (`"` + strings.ReplaceAll(argument, `"`, `""`) + `"`)
+}
+
+func (p *Provisioner) getDefaultExecuteCommand(pwshPath string) string {
+	isWindows := ("windows" == p.config.OsType)
+	quoteArgument := quoteLinuxArgument
+
+	if isWindows {
+		quoteArgument = quoteWindowsArgument
+	}
+
+	arguments := []string{"-ExecutionPolicy", quoteArgument(p.config.ExecutionPolicy), "-NoLogo", "-NonInteractive"}
+
+	if !p.config.NoProfile.False() {
+		arguments = append(arguments, "-NoProfile")
+	}
+
+	for _, extraArgument := range p.config.ExtraArguments {
+		arguments = append(arguments, quoteArgument(extraArgument))
+	}
+
+	if p.config.UseFileParameter {
+		arguments = append(arguments, "-File", `"{{.Path}}"`)
+	} else if isWindows {
+		arguments = append(arguments, "-Command", (`"` + p.getScriptInvocation() + `"`))
+	} else {
+		arguments = append(arguments, "-Command", (`"` + strings.ReplaceAll(p.getScriptInvocation(), "$", `\$`) + `"`))
+	}
+
+	if isWindows {
+		return (`"` + pwshPath + `" ` + strings.Join(arguments, " "))
+	} else {
+		command := (quoteArgument(pwshPath) + " " + strings.Join(arguments, " "))
+
+		if "" == p.config.RunAsUser {
+			command = ("chmod +x {{.Path}} && " + command)
+		}
+
+		return command
+	}
+}
+func (p *Provisioner) getScriptInvocation() string {
+	scriptInvocation := `$ErrorActionPreference = '` + p.config.ErrorActionPreference + `'; `
+	scriptInvocation += `$ProgressPreference = '` + p.config.ProgressPreference + `'; `
+
+	if p.hasWorkingDirectory() {
+		scriptInvocation += `$workingDirectory = '{{.WorkingDirectory}}'; `
+		scriptInvocation += `if ($workingDirectory) { New-Item -Force -ItemType Directory -Path $workingDirectory | Out-Null; Set-Location -Path $workingDirectory; } `
+		scriptInvocation += `Remove-Variable -Name workingDirectory; `
+	}
+
+	if p.hasEnvVars() {
+		scriptInvocation += `. '{{.Vars}}'; `
+	}
+
+	if 0 < len(p.config.SensitiveEnv) {
+		// WinRM does not forward stdin, so Windows receives the encoded values as part of the command instead.
+		if "windows" == p.config.OsType {
+			scriptInvocation += `$sensitiveEnv = '{{.SensitiveEnv}}'; `
+		} else {
+			scriptInvocation += `$sensitiveEnv = [Console]::In.ReadLine(); `
+		}
+
+		scriptInvocation += `if ($sensitiveEnv) { (ConvertFrom-Json -InputObject ([Text.Encoding]::UTF8.GetString([Convert]::FromBase64String($sensitiveEnv)))).PSObject.Properties | ForEach-Object { [Environment]::SetEnvironmentVariable($_.Name, $_.Value); }; } `
+		scriptInvocation += `Remove-Variable -Name sensitiveEnv; `
+	}
+
+	scriptInvocation += `try { &'{{.Path}}'; exit $LastExitCode; } catch { `
+	scriptInvocation += `$errorRecord = $_; `
+	scriptInvocation += `[Console]::Error.WriteLine(('` + pwshErrorRecordPrefix + `{0}' -f (ConvertTo-Json -Compress -InputObject ([ordered]@{ `
+	scriptInvocation += `Category = $errorRecord.CategoryInfo.ToString(); `
+	scriptInvocation += `Line = $errorRecord.InvocationInfo.ScriptLineNumber; `
+	scriptInvocation += `Message = $errorRecord.Exception.Message; `
+	scriptInvocation += `Position = $errorRecord.InvocationInfo.PositionMessage; `
+	scriptInvocation += `ScriptName = $errorRecord.InvocationInfo.ScriptName; `
+	scriptInvocation += `ScriptStackTrace = $errorRecord.ScriptStackTrace; `
+	scriptInvocation += `})))); exit 1; }`
+
+	return scriptInvocation
+}
+func (p *Provisioner) hasWorkingDirectory() bool {
+	if "" != p.config.WorkingDirectory {
+		return true
+	}
+
+	for _, scriptOverride := range p.config.ScriptOverrides {
+		if "" != scriptOverride.WorkingDirectory {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,172 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakeCommandResponse struct {
+	delay      time.Duration
+	disconnect bool
+	exitCode   int
+	stderr     string
+	stdout     string
+}
+type fakeCommandRule struct {
+	command   string
+	responses []fakeCommandResponse
+	script    string
+}
+type fakeCommunicator struct {
+	commands    []string
+	directories map[string]string
+	downloads   map[string]string
+	files       map[string]string
+	lastUpload  string
+	mutex       sync.Mutex
+	rules       []*fakeCommandRule
+	stdin       []string
+	uploads     map[string]string
+}
+
+func newFakeCommunicator(rules ...*fakeCommandRule) *fakeCommunicator {
+	return &fakeCommunicator{
+		commands:    make([]string, 0),
+		directories: make(map[string]string),
+		downloads:   make(map[string]string),
+		files:       make(map[string]string),
+		rules:       rules,
+		stdin:       make([]string, 0),
+		uploads:     make(map[string]string),
+	}
+}
+
+func (c *fakeCommunicator) Commands(substring string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	commands := make([]string, 0)
+
+	for _, command := range c.commands {
+		if strings.Contains(command, substring) {
+			commands = append(commands, command)
+		}
+	}
+
+	return commands
+}
+func (c *fakeCommunicator) Download(path string, writer io.Writer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if content, ok := c.files[path]; !ok {
+		return fmt.Errorf("file not found: %s", path)
+	} else {
+		_, e := io.WriteString(writer, content)
+
+		return e
+	}
+}
+func (c *fakeCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.downloads[dst] = src
+
+	return nil
+}
+func (c *fakeCommunicator) Start(ctx context.Context, remoteCmd *packersdk.RemoteCmd) error {
+	c.mutex.Lock()
+
+	c.commands = append(c.commands, remoteCmd.Command)
+	response := c.nextResponse(remoteCmd.Command)
+
+	if nil != remoteCmd.Stdin {
+		var buffer bytes.Buffer
+
+		if _, e := io.Copy(&buffer, remoteCmd.Stdin); nil != e {
+			c.mutex.Unlock()
+
+			return e
+		}
+
+		c.stdin = append(c.stdin, buffer.String())
+	}
+
+	c.mutex.Unlock()
+
+	if response.disconnect {
+		return errors.New("remote host closed the connection")
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			remoteCmd.SetExited(packersdk.CmdDisconnect)
+
+			return
+		case <-time.After(response.delay):
+		}
+
+		if ("" != response.stdout) && (nil != remoteCmd.Stdout) {
+			io.WriteString(remoteCmd.Stdout, response.stdout)
+		}
+
+		if ("" != response.stderr) && (nil != remoteCmd.Stderr) {
+			io.WriteString(remoteCmd.Stderr, response.stderr)
+		}
+
+		remoteCmd.SetExited(response.exitCode)
+	}()
+
+	return nil
+}
+func (c *fakeCommunicator) Upload(path string, reader io.Reader, fileInfo *os.FileInfo) error {
+	var buffer bytes.Buffer
+
+	if _, e := io.Copy(&buffer, reader); nil != e {
+		return e
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lastUpload = buffer.String()
+	c.uploads[path] = buffer.String()
+
+	return nil
+}
+func (c *fakeCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.directories[dst] = src
+
+	return nil
+}
+func (c *fakeCommunicator) nextResponse(command string) fakeCommandResponse {
+	for _, rule := range c.rules {
+		if !strings.Contains(command, rule.command) || !strings.Contains(c.lastUpload, rule.script) || (0 == len(rule.responses)) {
+			continue
+		}
+
+		response := rule.responses[0]
+
+		if 1 < len(rule.responses) {
+			rule.responses = rule.responses[1:]
+		}
+
+		return response
+	}
+
+	return fakeCommandResponse{}
+}
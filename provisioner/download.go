@@ -0,0 +1,92 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+//go:embed download.ps1
+var downloadTemplatePs1 string
+var downloadTemplate = template.Must(template.New("Download").Parse(downloadTemplatePs1))
+
+func (p *Provisioner) downloadArtifact(ctx context.Context, download Download, ui packersdk.Ui) error {
+	var buffer bytes.Buffer
+
+	if e := downloadTemplate.Execute(&buffer, map[string]string{
+		"Prefix": pwshDownloadPrefix,
+		"Source": strings.ReplaceAll(download.Source, "'", "''"),
+	}); nil != e {
+		return fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	} else if listScriptPath, e := p.getInlineScriptFilePath([]string{buffer.String()}); nil != e {
+		return e
+	} else {
+		captureUi := newCaptureUi(ui, pwshDownloadPrefix)
+
+		if exitCode, e := p.uploadAndExecuteScript(ctx, p.config.RemotePath, listScriptPath, 0, captureUi); nil != e {
+			return e
+		} else if 0 != exitCode {
+			return fmt.Errorf(pwshDownloadingErrorFormat, fmt.Sprintf("%s; exit code: %d", download.Source, exitCode))
+		} else if 0 == len(captureUi.Lines()) {
+			return fmt.Errorf(pwshDownloadingErrorFormat, fmt.Sprintf("%s; no matching items", download.Source))
+		} else {
+			items := captureUi.Lines()
+			isDirectoryDestination := ((1 < len(items)) || os.IsPathSeparator(download.Destination[len(download.Destination)-1]))
+
+			if destinationFileInfo, e := os.Stat(download.Destination); (nil == e) && destinationFileInfo.IsDir() {
+				isDirectoryDestination = true
+			}
+
+			for _, item := range items {
+				if pair := strings.SplitN(item, ":", 2); 2 == len(pair) {
+					itemPath := pair[1]
+					localPath := download.Destination
+
+					if isDirectoryDestination {
+						localPath = filepath.Join(download.Destination, itemPath[(strings.LastIndexAny(itemPath, `/\`)+1):])
+					}
+
+					ui.Say(fmt.Sprintf("Downloading artifact; remote path: %s; local path: %s", itemPath, localPath))
+
+					if "D" == pair[0] {
+						if e = p.communicator.DownloadDir(itemPath, localPath, nil); nil != e {
+							return fmt.Errorf(pwshDownloadingErrorFormat, e)
+						}
+					} else if e = p.downloadFile(itemPath, localPath); nil != e {
+						return fmt.Errorf(pwshDownloadingErrorFormat, e)
+					}
+				}
+			}
+
+			return nil
+		}
+	}
+}
+func (p *Provisioner) downloadArtifacts(ctx context.Context, ui packersdk.Ui) error {
+	for _, download := range p.config.Downloads {
+		if e := p.downloadArtifact(ctx, download, ui); nil != e {
+			return e
+		}
+	}
+
+	return nil
+}
+func (p *Provisioner) downloadFile(remotePath string, localPath string) error {
+	if e := os.MkdirAll(filepath.Dir(localPath), 0755); nil != e {
+		return e
+	} else if fileHandle, e := os.Create(localPath); nil != e {
+		return e
+	} else {
+		defer fileHandle.Close()
+
+		return p.communicator.Download(remotePath, fileHandle)
+	}
+}
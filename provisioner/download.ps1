@@ -0,0 +1,10 @@
+Get-Item -Force -Path '{{.Source}}' | ForEach-Object {
+    if ($_.PSIsContainer) {
+        $itemType = 'D';
+    }
+    else {
+        $itemType = 'F';
+    }
+
+    Write-Output ('{{.Prefix}}{0}:{1}' -f $itemType, $_.FullName);
+}
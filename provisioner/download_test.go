@@ -0,0 +1,85 @@
+package pwsh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestProvisioner_DownloadArtifacts(t *testing.T) {
+	testCases := []struct {
+		name                string
+		source              string
+		destination         string
+		listing             string
+		isErrorExpected     bool
+		expectedFiles       map[string]string
+		expectedDirectories map[string]string
+	}{
+		{
+			name:          "file",
+			source:        "/var/log/app.log",
+			destination:   "report.log",
+			listing:       (pwshDownloadPrefix + "F:/var/log/app.log\n"),
+			expectedFiles: map[string]string{"report.log": "app\n"},
+		},
+		{
+			name:          "glob",
+			source:        "/var/log/*.log",
+			destination:   "logs",
+			listing:       (pwshDownloadPrefix + "F:/var/log/app.log\n" + pwshDownloadPrefix + "F:/var/log/setup.log\n"),
+			expectedFiles: map[string]string{filepath.Join("logs", "app.log"): "app\n", filepath.Join("logs", "setup.log"): "setup\n"},
+		},
+		{
+			name:                "directory",
+			source:              "/var/log/reports",
+			destination:         ("artifacts" + string(os.PathSeparator)),
+			listing:             (pwshDownloadPrefix + "D:/var/log/reports\n"),
+			expectedDirectories: map[string]string{filepath.Join("artifacts", "reports"): "/var/log/reports"},
+		},
+		{
+			name:            "missing",
+			source:          "/var/log/missing.log",
+			destination:     "missing.log",
+			isErrorExpected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			directory := t.TempDir()
+			p, communicator := testProvisioner(t, map[string]interface{}{
+				"download": []map[string]interface{}{{"destination": (directory + string(os.PathSeparator) + testCase.destination), "source": testCase.source}},
+				"inline":   []string{"Write-Output 'Hello, world!';"},
+				"os_type":  "ubuntu",
+			}, &fakeCommandRule{script: "Get-Item", responses: []fakeCommandResponse{{stdout: testCase.listing}}})
+			communicator.files["/var/log/app.log"] = "app\n"
+			communicator.files["/var/log/setup.log"] = "setup\n"
+
+			if e := p.downloadArtifacts(context.Background(), packersdk.TestUi(t)); testCase.isErrorExpected != (nil != e) {
+				t.Fatalf("expected error: %t, got %v", testCase.isErrorExpected, e)
+			}
+
+			for localPath, expected := range testCase.expectedFiles {
+				if content, e := os.ReadFile(filepath.Join(directory, localPath)); nil != e {
+					t.Errorf("expected %s to be downloaded: %s", localPath, e)
+				} else if expected != string(content) {
+					t.Errorf("expected %s to contain %q, got %q", localPath, expected, string(content))
+				}
+			}
+
+			if len(testCase.expectedDirectories) != len(communicator.downloads) {
+				t.Errorf("expected directory downloads %v, got %v", testCase.expectedDirectories, communicator.downloads)
+			}
+
+			for localPath, expected := range testCase.expectedDirectories {
+				if actual := communicator.downloads[filepath.Join(directory, localPath)]; expected != actual {
+					t.Errorf("expected %s to be downloaded into %s, got %q", expected, localPath, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+	"github.com/hashicorp/packer-plugin-sdk/uuid"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+const (
+	dscApplyingErrorFormat  = "Error applying DSC configuration: %s."
+	dscUploadingErrorFormat = "Error uploading DSC configuration: %s."
+)
+
+//go:embed dsc.ps1
+var dscTemplatePs1 string
+var dscTemplate = template.Must(template.New("Dsc").Parse(dscTemplatePs1))
+
+type DscConfig struct {
+	Config `mapstructure:",squash"`
+
+	Configuration string   `mapstructure:"configuration"`
+	Modules       []string `mapstructure:"modules"`
+	RebootLimit   *int     `mapstructure:"reboot_limit"`
+	RemoteDscPath string   `mapstructure:"remote_dsc_path"`
+}
+type DscProvisioner struct {
+	config      DscConfig
+	provisioner Provisioner
+}
+
+func (p *DscProvisioner) ConfigSpec() hcldec.ObjectSpec {
+	return p.config.FlatMapstructure().HCL2Spec()
+}
+func (p *DscProvisioner) Prepare(raws ...interface{}) error {
+	if e := decodeConfig(&p.config, &p.config.ctx, raws...); nil != e {
+		return e
+	}
+
+	var e error
+
+	if nil == p.config.RebootLimit {
+		rebootLimit := 3
+		p.config.RebootLimit = &rebootLimit
+	}
+
+	if (nil != p.config.Inline) || (0 < len(p.config.Scripts)) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'inline' and 'scripts' parameters are not supported; the DSC runner script is generated."))
+	}
+
+	if p.config.RebootIsEnabled {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'reboot_is_enabled' parameter is not supported; the machine is rebooted whenever the DSC configuration requests it."))
+	}
+
+	if 0 > *p.config.RebootLimit {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'reboot_limit' parameter must not be negative: %d.", *p.config.RebootLimit))
+	}
+
+	if strings.Contains(p.config.RemoteDscPath, "'") {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'remote_dsc_path' parameter must not contain single quotes."))
+	}
+
+	if "" == p.config.Configuration {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'configuration' parameter must be specified."))
+	} else if _, err := os.Stat(p.config.Configuration); nil != err {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'configuration' path could not be read: %s.", err))
+	} else if "2" == p.getDscVersion() {
+		if "windows" != strings.ToLower(p.config.OsType) {
+			e = packersdk.MultiErrorAppend(e, errors.New("MOF configurations require 'os_type' to be 'windows'."))
+		}
+
+		// The local configuration manager cmdlets are only available to Windows PowerShell.
+		if "" == p.config.PowershellEdition {
+			p.config.PowershellEdition = "desktop"
+		}
+	} else if "" == p.getDscVersion() {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'configuration' parameter must be a '.json', '.mof', '.yaml' or '.yml' document: %s.", p.config.Configuration))
+	}
+
+	for _, module := range p.config.Modules {
+		if fileInfo, err := os.Stat(module); nil != err {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'modules' path could not be read: %s.", err))
+		} else if !fileInfo.IsDir() {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'modules' path must be a directory: %s.", module))
+		}
+	}
+
+	if strings.EqualFold("AllSigned", p.config.ExecutionPolicy) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'AllSigned' execution policy is not supported; the DSC runner script is generated and cannot be signed."))
+	}
+
+	p.provisioner.config = p.config.Config
+
+	if err := p.provisioner.prepareConfig(); nil != err {
+		e = packersdk.MultiErrorAppend(e, err)
+	}
+
+	if nil != e {
+		return e
+	}
+
+	if "" == p.config.RemoteDscPath {
+		p.config.RemoteDscPath = fmt.Sprintf("%s/packer-pwsh-dsc-%s", path.Dir(p.provisioner.config.RemotePath), uuid.TimeOrderedUUID())
+	}
+
+	var buffer bytes.Buffer
+
+	if e = dscTemplate.Execute(&buffer, map[string]string{
+		"ConfigurationPath": strings.ReplaceAll(p.getRemoteConfigurationPath(), "'", "''"),
+		"DscPath":           p.config.RemoteDscPath,
+		"OutputPrefix":      strings.ReplaceAll(p.provisioner.config.OutputPrefix, "'", "''"),
+		"Version":           p.getDscVersion(),
+	}); nil != e {
+		return fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	}
+
+	p.provisioner.config.Inline = []string{buffer.String()}
+
+	return nil
+}
+func (p *DscProvisioner) Provision(ctx context.Context, ui packersdk.Ui, communicator packersdk.Communicator, generatedData map[string]interface{}) error {
+	if "local" == p.provisioner.config.ExecutionTarget {
+		communicator = newLocalCommunicator()
+	}
+
+	if e := p.uploadConfiguration(communicator, ui); nil != e {
+		return e
+	}
+
+	changedResources := make([]string, 0)
+
+	for rebootCount := 0; ; rebootCount++ {
+		if 0 == rebootCount {
+			if e := p.provisioner.Provision(ctx, ui, communicator, generatedData); nil != e {
+				return e
+			}
+		} else if e := p.provisioner.reprovision(ctx, ui); nil != e {
+			return e
+		}
+
+		if value, ok := generatedData[pwshOutputKeyPrefix+"DscChangedResources"].(string); ok && ("" != value) {
+			changedResources = append(changedResources, strings.Split(value, ";")...)
+		}
+
+		if !strings.EqualFold("True", fmt.Sprint(generatedData[pwshOutputKeyPrefix+"DscRebootRequired"])) {
+			break
+		} else if *p.config.RebootLimit <= rebootCount {
+			return fmt.Errorf(dscApplyingErrorFormat, fmt.Sprintf("the configuration requested more than %d reboot(s)", *p.config.RebootLimit))
+		} else if ("local" == p.provisioner.config.ExecutionTarget) || ("" == p.provisioner.config.RebootInitiateCommand) {
+			return fmt.Errorf(dscApplyingErrorFormat, "the configuration requested a reboot that cannot be performed on this target")
+		} else if e := p.provisioner.rebootMachine(ctx, ui); nil != e {
+			return fmt.Errorf(dscApplyingErrorFormat, e)
+		}
+
+		ui.Say("Re-applying DSC configuration after reboot...")
+	}
+
+	if 0 == len(changedResources) {
+		ui.Say("Applied DSC configuration; no resources were changed")
+	} else {
+		ui.Say(fmt.Sprintf("Applied DSC configuration; changed resources: %s", strings.Join(changedResources, ", ")))
+	}
+
+	return nil
+}
+
+func (p *DscProvisioner) getDscVersion() string {
+	switch strings.ToLower(filepath.Ext(p.config.Configuration)) {
+	case ".json", ".yaml", ".yml":
+		return "3"
+	case ".mof":
+		return "2"
+	default:
+		return ""
+	}
+}
+func (p *DscProvisioner) getRemoteConfigurationPath() string {
+	return (p.config.RemoteDscPath + "/" + p.getStagedConfigurationName())
+}
+func (p *DscProvisioner) getStagedConfigurationName() string {
+	// Start-DscConfiguration applies the document named after the target node.
+	if "2" == p.getDscVersion() {
+		return "localhost.mof"
+	}
+
+	return filepath.Base(p.config.Configuration)
+}
+func (p *DscProvisioner) uploadConfiguration(communicator packersdk.Communicator, ui packersdk.Ui) error {
+	ui.Say(fmt.Sprintf("Uploading DSC configuration; remote path: %s", p.config.RemoteDscPath))
+
+	if stagingPath, e := tmp.Dir("pwsh-dsc"); nil != e {
+		return fmt.Errorf(dscUploadingErrorFormat, e)
+	} else {
+		defer os.RemoveAll(stagingPath)
+
+		if e = copyLocalDirectory(p.config.Configuration, filepath.Join(stagingPath, p.getStagedConfigurationName()), nil); nil != e {
+			return fmt.Errorf(dscUploadingErrorFormat, e)
+		}
+
+		for _, module := range p.config.Modules {
+			if e = copyLocalDirectory(module, filepath.Join(stagingPath, "Modules", filepath.Base(filepath.Clean(module))), nil); nil != e {
+				return fmt.Errorf(dscUploadingErrorFormat, e)
+			}
+		}
+
+		if e = communicator.UploadDir(p.config.RemoteDscPath, (stagingPath + string(os.PathSeparator)), nil); nil != e {
+			return fmt.Errorf(dscUploadingErrorFormat, e)
+		}
+
+		return nil
+	}
+}
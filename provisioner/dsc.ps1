@@ -0,0 +1,61 @@
+$ErrorActionPreference = 'Stop';
+$ProgressPreference = 'SilentlyContinue';
+
+$changedResources = [Collections.Generic.List[string]]::new();
+$configurationPath = '{{.ConfigurationPath}}';
+$dscPath = '{{.DscPath}}';
+$modulePath = (Join-Path -Path $dscPath -ChildPath 'Modules');
+$rebootRequired = $false;
+
+if (Test-Path -PathType Container -Path $modulePath) {
+    $env:PSModulePath = ('{0}{1}{2}' -f $modulePath, [IO.Path]::PathSeparator, $env:PSModulePath);
+}
+{{if eq .Version "2"}}
+if (Test-Path -PathType Container -Path $modulePath) {
+    # The local configuration manager only resolves resource modules from the machine module path.
+    Copy-Item -Force -Path (Join-Path -Path $modulePath -ChildPath '*') -Recurse -Destination (Join-Path -Path $env:ProgramFiles -ChildPath 'WindowsPowerShell/Modules');
+}
+
+$driftedResources = @((Test-DscConfiguration -ReferenceConfiguration $configurationPath).ResourcesNotInDesiredState | ForEach-Object { $_.ResourceId; });
+
+Start-DscConfiguration -Force -Path $dscPath -Verbose -Wait;
+
+# Only the drifted resources that the configuration brought into the desired state were actually changed.
+$remainingResources = @((Test-DscConfiguration -ReferenceConfiguration $configurationPath).ResourcesNotInDesiredState | ForEach-Object { $_.ResourceId; });
+
+foreach ($resource in $driftedResources) {
+    if ($remainingResources -notcontains $resource) {
+        $changedResources.Add($resource);
+    }
+}
+
+$rebootRequired = ('PendingReboot' -eq (Get-DscLocalConfigurationManager).LCMState);
+{{else}}
+if (-not (Get-Command -ErrorAction SilentlyContinue -Name 'dsc')) {
+    throw 'The DSC command line (dsc) was not found.';
+}
+
+$output = (& dsc config set --file $configurationPath --output-format json | Out-String);
+
+if (0 -ne $LastExitCode) {
+    throw ('The DSC configuration could not be applied; exit code: {0}' -f $LastExitCode);
+}
+
+$setResult = ($output | ConvertFrom-Json);
+
+foreach ($resource in $setResult.results) {
+    if ($resource.result.changedProperties) {
+        $changedResources.Add(('[{0}]{1}' -f $resource.type, $resource.name));
+    }
+}
+
+$rebootRequired = [bool]$setResult.metadata.'Microsoft.DSC'.restartRequired;
+{{end}}
+foreach ($changedResource in $changedResources) {
+    Write-Output ('Changed resource: {0}' -f $changedResource);
+}
+
+Write-Output ('{{.OutputPrefix}}DscChangedResources={0}' -f ($changedResources -join ';'));
+Write-Output ('{{.OutputPrefix}}DscRebootRequired={0}' -f $rebootRequired);
+
+exit 0;
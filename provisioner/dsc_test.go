@@ -0,0 +1,158 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testDscConfig(t *testing.T, configurationName string) map[string]interface{} {
+	directory := t.TempDir()
+	configurationPath := filepath.Join(directory, configurationName)
+	modulePath := filepath.Join(directory, "ExampleDsc")
+
+	if e := os.WriteFile(configurationPath, []byte("resources: []\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.MkdirAll(modulePath, 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.WriteFile(filepath.Join(modulePath, "ExampleDsc.psd1"), []byte("@{}\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	return map[string]interface{}{
+		"configuration": configurationPath,
+		"modules":       []string{modulePath},
+		"os_type":       "windows",
+	}
+}
+
+func TestDscProvisioner_Prepare(t *testing.T) {
+	testCases := []struct {
+		name              string
+		configurationName string
+		overrides         map[string]interface{}
+		isErrorExpected   bool
+		expectedEdition   string
+	}{
+		{name: "mof", configurationName: "localhost.mof", expectedEdition: "desktop"},
+		{name: "yaml", configurationName: "web.dsc.yaml", expectedEdition: "auto"},
+		{name: "mof on linux", configurationName: "localhost.mof", overrides: map[string]interface{}{"os_type": "ubuntu"}, isErrorExpected: true},
+		{name: "unknown document", configurationName: "web.txt", isErrorExpected: true},
+		{name: "all signed", configurationName: "web.dsc.yaml", overrides: map[string]interface{}{"execution_policy": "AllSigned"}, isErrorExpected: true},
+		{name: "inline", configurationName: "web.dsc.yaml", overrides: map[string]interface{}{"inline": []string{"exit 0;"}}, isErrorExpected: true},
+		{name: "reboot is enabled", configurationName: "web.dsc.yaml", overrides: map[string]interface{}{"reboot_is_enabled": true}, isErrorExpected: true},
+		{name: "missing module", configurationName: "web.dsc.yaml", overrides: map[string]interface{}{"modules": []string{"missing"}}, isErrorExpected: true},
+	}
+
+	for _, testCase := range testCases {
+		config := testDscConfig(t, testCase.configurationName)
+
+		for key, value := range testCase.overrides {
+			config[key] = value
+		}
+
+		p := new(DscProvisioner)
+		e := p.Prepare(config)
+
+		if testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%s: expected error: %t, got %v", testCase.name, testCase.isErrorExpected, e)
+		} else if (nil == e) && (testCase.expectedEdition != p.provisioner.config.PowershellEdition) {
+			t.Errorf("%s: expected edition %q, got %q", testCase.name, testCase.expectedEdition, p.provisioner.config.PowershellEdition)
+		}
+	}
+}
+func TestDscProvisioner_Provision(t *testing.T) {
+	testRebootPollInterval(t)
+
+	testCases := []struct {
+		name             string
+		rebootLimit      interface{}
+		responses        []fakeCommandResponse
+		isErrorExpected  bool
+		expectedReboots  int
+		expectedResource string
+	}{
+		{
+			name:      "no changes",
+			responses: []fakeCommandResponse{{stdout: "packer-output:DscChangedResources=\npacker-output:DscRebootRequired=False\n"}},
+		},
+		{
+			name: "reboot",
+			responses: []fakeCommandResponse{
+				{stdout: "packer-output:DscChangedResources=[WindowsFeature]Web\npacker-output:DscRebootRequired=True\n"},
+				{stdout: "packer-output:DscChangedResources=[File]Site\npacker-output:DscRebootRequired=False\n"},
+			},
+			expectedReboots:  1,
+			expectedResource: "[File]Site",
+		},
+		{
+			name:            "reboot limit",
+			rebootLimit:     1,
+			responses:       []fakeCommandResponse{{stdout: "packer-output:DscChangedResources=\npacker-output:DscRebootRequired=True\n"}},
+			isErrorExpected: true,
+			expectedReboots: 1,
+		},
+		{
+			name:            "reboot limit zero",
+			rebootLimit:     0,
+			responses:       []fakeCommandResponse{{stdout: "packer-output:DscChangedResources=\npacker-output:DscRebootRequired=True\n"}},
+			isErrorExpected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		config := testDscConfig(t, "web.dsc.yaml")
+
+		if nil != testCase.rebootLimit {
+			config["reboot_limit"] = testCase.rebootLimit
+		}
+
+		p := new(DscProvisioner)
+
+		if e := p.Prepare(config); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.name, e)
+		}
+
+		communicator := newFakeCommunicator(
+			&fakeCommandRule{command: "-EncodedCommand", responses: []fakeCommandResponse{{stdout: (pwshResolvePrefix + "C:/Program Files/PowerShell/7/pwsh.exe|Core|7.4.0\n")}}},
+			&fakeCommandRule{command: `packer reboot"`, responses: []fakeCommandResponse{{exitCode: 0}}},
+			&fakeCommandRule{command: "packer reboot test", responses: []fakeCommandResponse{{exitCode: 1}}},
+			&fakeCommandRule{command: `exit 0;`, responses: []fakeCommandResponse{{exitCode: 0}}},
+			&fakeCommandRule{script: "DscRebootRequired", responses: testCase.responses},
+		)
+		var output bytes.Buffer
+
+		ui := &packersdk.BasicUi{ErrorWriter: io.Discard, Reader: new(bytes.Buffer), Writer: &output}
+		e := p.Provision(context.Background(), ui, communicator, make(map[string]interface{}))
+
+		if testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%s: expected error: %t, got %v", testCase.name, testCase.isErrorExpected, e)
+		}
+
+		if actual := len(communicator.Commands(p.provisioner.config.RebootInitiateCommand)); testCase.expectedReboots != actual {
+			t.Errorf("%s: expected %d reboots, got %d", testCase.name, testCase.expectedReboots, actual)
+		}
+
+		if actual := len(communicator.Commands("-EncodedCommand")); 1 != actual {
+			t.Errorf("%s: expected the installation to be resolved once, got %d", testCase.name, actual)
+		}
+
+		if src, ok := communicator.directories[p.config.RemoteDscPath]; !ok {
+			t.Errorf("%s: expected the configuration to be uploaded to %q", testCase.name, p.config.RemoteDscPath)
+		} else if _, e := os.Stat(filepath.Join(src, "Modules", "ExampleDsc", "ExampleDsc.psd1")); nil == e {
+			t.Errorf("%s: expected the staging directory to be removed after the upload", testCase.name)
+		}
+
+		if "" != testCase.expectedResource {
+			if !strings.Contains(output.String(), ("changed resources: [WindowsFeature]Web, " + testCase.expectedResource)) {
+				t.Errorf("%s: expected the summary to include %q, got %q", testCase.name, testCase.expectedResource, output.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package pwsh
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func formatElevatedCommand(format string, builtInFormat string, command string) string {
+	// Only the built-in formats expect a quoted argument; a custom format receives the command as is.
+	if builtInFormat == format {
+		return fmt.Sprintf(format, quoteLinuxArgument(command))
+	}
+
+	return fmt.Sprintf(format, command)
+}
+func getLinuxElevatedExecuteCommandFormat(elevationMethod string, isPasswordProvided bool) string {
+	switch elevationMethod {
+	case "doas":
+		return `doas -n sh -e -c %s`
+	case "su":
+		return `su -s /bin/sh -c %s root`
+	default:
+		if isPasswordProvided {
+			return `sudo -S -p '' sh -e -c %s`
+		}
+
+		return `sudo -n sh -e -c %s`
+	}
+}
+func getLinuxRunAsExecuteCommandFormat(elevationMethod string, isPasswordProvided bool, runAsUser string) string {
+	switch elevationMethod {
+	case "doas":
+		return fmt.Sprintf(`chmod a+rx {{.Path}} && doas -n -u %s sh -e -c %%s`, runAsUser)
+	case "su":
+		return fmt.Sprintf(`chmod a+rx {{.Path}} && su -s /bin/sh -c %%s %s`, runAsUser)
+	default:
+		if isPasswordProvided {
+			return fmt.Sprintf(`chmod a+rx {{.Path}} && sudo -S -p '' -u %s sh -e -c %%s`, runAsUser)
+		}
+
+		return fmt.Sprintf(`chmod a+rx {{.Path}} && sudo -n -u %s sh -e -c %%s`, runAsUser)
+	}
+}
+func isElevationMethod(elevationMethod string) bool {
+	return ("doas" == elevationMethod) || ("su" == elevationMethod) || ("sudo" == elevationMethod)
+}
+
+func (p *Provisioner) getStdinReader() io.Reader {
+	// Only the elevation method reads stdin; a password it never asks for (NOPASSWD, cached credentials) is simply left unread.
+	if p.isElevatedPasswordPiped() {
+		return strings.NewReader(p.ElevatedPassword() + "\n")
+	}
+
+	return nil
+}
+func (p *Provisioner) isElevatedPasswordPiped() bool {
+	// Only sudo (-S) reads the password from stdin; doas and su both require a terminal.
+	return ("windows" != p.config.OsType) && ("" != p.config.ElevatedUser) && ("" != p.config.ElevatedPassword) && ("sudo" == p.config.ElevationMethod)
+}
+func (p *Provisioner) wrapElevatedCommand(command string) string {
+	if "windows" == p.config.OsType {
+		return fmt.Sprintf(p.config.ElevatedExecuteCommand, command)
+	}
+
+	return formatElevatedCommand(p.config.ElevatedExecuteCommand, getLinuxElevatedExecuteCommandFormat(p.config.ElevationMethod, ("" != p.config.ElevatedPassword)), command)
+}
+func (p *Provisioner) wrapRunAsCommand(command string) string {
+	command = fmt.Sprintf(`export HOME="$(getent passwd %s | cut -d: -f6)" && %s`, p.config.RunAsUser, command)
+
+	return formatElevatedCommand(p.config.RunAsExecuteCommand, getLinuxRunAsExecuteCommandFormat(p.config.ElevationMethod, ("" != p.config.ElevatedPassword), p.config.RunAsUser), command)
+}
@@ -0,0 +1,238 @@
+package pwsh
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testElevationConfig(elevationMethod string, elevatedPassword string) map[string]interface{} {
+	return map[string]interface{}{
+		"elevated_password": elevatedPassword,
+		"elevated_user":     "packer",
+		"elevation_method":  elevationMethod,
+		"inline":            []string{"Write-Output 'Hello, world!';"},
+		"os_type":           "ubuntu",
+	}
+}
+
+func TestGetLinuxElevatedExecuteCommandFormat(t *testing.T) {
+	testCases := []struct {
+		elevationMethod    string
+		isPasswordProvided bool
+		expected           string
+	}{
+		{"doas", false, `doas -n sh -e -c %s`},
+		{"su", false, `su -s /bin/sh -c %s root`},
+		{"su", true, `su -s /bin/sh -c %s root`},
+		{"sudo", false, `sudo -n sh -e -c %s`},
+		{"sudo", true, `sudo -S -p '' sh -e -c %s`},
+	}
+
+	for _, testCase := range testCases {
+		if actual := getLinuxElevatedExecuteCommandFormat(testCase.elevationMethod, testCase.isPasswordProvided); testCase.expected != actual {
+			t.Errorf("%s (password: %t): expected %q, got %q", testCase.elevationMethod, testCase.isPasswordProvided, testCase.expected, actual)
+		}
+	}
+}
+func TestProvisioner_ElevatedExecuteCommand(t *testing.T) {
+	testCases := []struct {
+		elevationMethod  string
+		elevatedPassword string
+		expectedPrefix   string
+	}{
+		{"doas", "", `doas -n sh -e -c 'chmod +x {{.Path}} && `},
+		{"su", "", `su -s /bin/sh -c 'chmod +x {{.Path}} && `},
+		{"sudo", "", `sudo -n sh -e -c 'chmod +x {{.Path}} && `},
+		{"sudo", "secret", `sudo -S -p '' sh -e -c 'chmod +x {{.Path}} && `},
+	}
+
+	for _, testCase := range testCases {
+		p := new(Provisioner)
+
+		if e := p.Prepare(testElevationConfig(testCase.elevationMethod, testCase.elevatedPassword)); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.elevationMethod, e)
+		}
+
+		command := p.ElevatedExecuteCommand()
+
+		if !strings.HasPrefix(command, testCase.expectedPrefix) {
+			t.Errorf("%s: expected prefix %q, got %q", testCase.elevationMethod, testCase.expectedPrefix, command)
+		}
+
+		if strings.Contains(command, "echo") {
+			t.Errorf("%s: command must not echo anything through a pipeline: %q", testCase.elevationMethod, command)
+		}
+	}
+}
+func TestProvisioner_ElevatedExecuteCommandPiping(t *testing.T) {
+	shellPath, e := exec.LookPath("sh")
+
+	if nil != e {
+		t.Skip("sh is not available")
+	}
+
+	// The fake sudo consumes exactly one password line, like 'sudo -S', and then runs the remaining arguments.
+	binPath := t.TempDir()
+	fakeSudo := "#!/bin/sh\nwhile [ \"sh\" != \"$1\" ]; do shift; done\nIFS= read -r password\n[ \"secret\" = \"$password\" ] || exit 42\nexec \"$@\"\n"
+
+	if e = os.WriteFile(filepath.Join(binPath, "sudo"), []byte(fakeSudo), 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	testCases := []struct {
+		name                   string
+		elevatedExecuteCommand string
+	}{
+		{name: "default"},
+		{name: "custom", elevatedExecuteCommand: `sudo -S -p '' sh -e -c '%s'`},
+	}
+
+	for _, testCase := range testCases {
+		config := testElevationConfig("sudo", "secret")
+		config["elevated_execute_command"] = testCase.elevatedExecuteCommand
+		p := new(Provisioner)
+
+		if e := p.Prepare(config); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.name, e)
+		}
+
+		command := p.wrapElevatedCommand(`printf "stdin:%s" "$(cat)"`)
+		shellCmd := exec.Command(shellPath, "-c", command)
+		shellCmd.Env = append(os.Environ(), ("PATH=" + binPath + string(os.PathListSeparator) + os.Getenv("PATH")))
+		shellCmd.Stdin = p.getStdinReader()
+
+		if output, e := shellCmd.Output(); nil != e {
+			t.Errorf("%s: unexpected error: %s (command: %q)", testCase.name, e, command)
+		} else if "stdin:" != string(output) {
+			t.Errorf("%s: expected the password to be consumed by sudo alone, got %q (command: %q)", testCase.name, string(output), command)
+		}
+	}
+}
+func TestProvisioner_GetStdinReader(t *testing.T) {
+	testCases := []struct {
+		elevationMethod  string
+		elevatedPassword string
+		expected         string
+	}{
+		{"doas", "", ""},
+		{"su", "", ""},
+		{"sudo", "", ""},
+		{"sudo", "secret", "secret\n"},
+	}
+
+	for _, testCase := range testCases {
+		p := new(Provisioner)
+
+		if e := p.Prepare(testElevationConfig(testCase.elevationMethod, testCase.elevatedPassword)); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.elevationMethod, e)
+		}
+
+		if reader := p.getStdinReader(); ("" == testCase.expected) && (nil != reader) {
+			t.Errorf("%s: expected no stdin", testCase.elevationMethod)
+		} else if nil != reader {
+			if actual, _ := io.ReadAll(reader); testCase.expected != string(actual) {
+				t.Errorf("%s: expected stdin %q, got %q", testCase.elevationMethod, testCase.expected, string(actual))
+			}
+		}
+	}
+}
+func TestProvisioner_GetStdinReaderWithSensitiveEnv(t *testing.T) {
+	config := testElevationConfig("sudo", "secret")
+	config["sensitive_env"] = map[string]string{"API_TOKEN": "token"}
+	p := new(Provisioner)
+
+	if e := p.Prepare(config); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if actual, _ := io.ReadAll(p.getStdinReader()); "secret\n" != string(actual) {
+		t.Errorf("expected the elevation stdin to only carry the password, got %q", string(actual))
+	}
+
+	if reader, e := p.getScriptStdinReader(); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if actual, _ := io.ReadAll(reader); !strings.HasPrefix(string(actual), "secret\n") || (2 != strings.Count(string(actual), "\n")) {
+		t.Errorf("expected the password line to precede the sensitive environment line, got %q", string(actual))
+	}
+}
+func TestProvisioner_PrepareElevationMethod(t *testing.T) {
+	testCases := []struct {
+		elevationMethod  string
+		elevatedPassword string
+		isErrorExpected  bool
+	}{
+		{"doas", "", false},
+		{"doas", "secret", true},
+		{"runas", "", true},
+		{"SU", "", false},
+		{"su", "secret", true},
+		{"sudo", "", false},
+	}
+
+	for _, testCase := range testCases {
+		p := new(Provisioner)
+
+		if e := p.Prepare(testElevationConfig(testCase.elevationMethod, testCase.elevatedPassword)); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%s (password: %q): expected error: %t, got %v", testCase.elevationMethod, testCase.elevatedPassword, testCase.isErrorExpected, e)
+		}
+	}
+}
+func TestProvisioner_RunAsUser(t *testing.T) {
+	testCases := []struct {
+		elevationMethod  string
+		elevatedPassword string
+		expectedPrefix   string
+	}{
+		{"doas", "", `chmod a+rx {{.Path}} && doas -n -u svc-app sh -e -c 'export HOME="$(getent passwd svc-app | cut -d: -f6)" && `},
+		{"su", "", `chmod a+rx {{.Path}} && su -s /bin/sh -c 'export HOME="$(getent passwd svc-app | cut -d: -f6)" && `},
+		{"sudo", "", `chmod a+rx {{.Path}} && sudo -n -u svc-app sh -e -c 'export HOME="$(getent passwd svc-app | cut -d: -f6)" && `},
+		{"sudo", "secret", `chmod a+rx {{.Path}} && sudo -S -p '' -u svc-app sh -e -c 'export HOME="$(getent passwd svc-app | cut -d: -f6)" && `},
+	}
+
+	for _, testCase := range testCases {
+		config := testElevationConfig(testCase.elevationMethod, testCase.elevatedPassword)
+		config["run_as_user"] = "svc-app"
+		p := new(Provisioner)
+
+		if e := p.Prepare(config); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.elevationMethod, e)
+		}
+
+		if command := p.wrapRunAsCommand(p.config.ExecuteCommand); !strings.HasPrefix(command, testCase.expectedPrefix) {
+			t.Errorf("%s: expected prefix %q, got %q", testCase.elevationMethod, testCase.expectedPrefix, command)
+		}
+	}
+
+	config := testElevationConfig("sudo", "")
+	config["os_type"] = "windows"
+	config["run_as_user"] = "svc-app"
+
+	if e := new(Provisioner).Prepare(config); nil == e {
+		t.Error("expected an error when 'run_as_user' is combined with Windows")
+	}
+}
+func TestQuoteLinuxArgument(t *testing.T) {
+	shellPath, e := exec.LookPath("sh")
+
+	if nil != e {
+		t.Skip("sh is not available")
+	}
+
+	for _, argument := range []string{
+		`chmod +x /tmp/script.ps1 && pwsh -Command "&'/tmp/script.ps1'; exit \$LastExitCode;"`,
+		`it's`,
+		`plain`,
+		`'`,
+		``,
+	} {
+		if output, e := exec.Command(shellPath, "-c", ("printf '%s' " + quoteLinuxArgument(argument))).Output(); nil != e {
+			t.Errorf("%q: unexpected error: %s", argument, e)
+		} else if argument != string(output) {
+			t.Errorf("%q: expected the shell to receive the argument unchanged, got %q", argument, string(output))
+		}
+	}
+}
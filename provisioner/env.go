@@ -0,0 +1,109 @@
+package pwsh
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+)
+
+func (p *Provisioner) getEnvVarScript() string {
+	var builder strings.Builder
+
+	writeEnvVar := func(name string, value string) {
+		builder.WriteString(fmt.Sprintf("[Environment]::SetEnvironmentVariable('%s', '%s');\n", name, strings.ReplaceAll(value, "'", "''")))
+	}
+
+	if "" != p.config.PackerBuildName {
+		writeEnvVar("PACKER_BUILD_NAME", p.config.PackerBuildName)
+	}
+
+	if "" != p.config.PackerBuilderType {
+		writeEnvVar("PACKER_BUILDER_TYPE", p.config.PackerBuilderType)
+	}
+
+	for _, envVar := range p.config.Vars {
+		if fields := strings.SplitN(envVar, "=", 2); 2 == len(fields) {
+			writeEnvVar(fields[0], fields[1])
+		}
+	}
+
+	names := make([]string, 0, len(p.config.Env))
+
+	for name := range p.config.Env {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		writeEnvVar(name, p.config.Env[name])
+	}
+
+	return builder.String()
+}
+func (p *Provisioner) getScriptStdinReader() (io.Reader, error) {
+	// The elevation method consumes the password line first; pwsh then reads the sensitive environment line.
+	readers := make([]io.Reader, 0)
+
+	if stdinReader := p.getStdinReader(); nil != stdinReader {
+		readers = append(readers, stdinReader)
+	}
+
+	if "windows" != p.config.OsType {
+		if sensitiveEnv, e := p.getSensitiveEnv(); nil != e {
+			return nil, e
+		} else if "" != sensitiveEnv {
+			readers = append(readers, strings.NewReader(sensitiveEnv+"\n"))
+		}
+	}
+
+	if 0 == len(readers) {
+		return nil, nil
+	}
+
+	return io.MultiReader(readers...), nil
+}
+func (p *Provisioner) getSensitiveEnv() (string, error) {
+	if 0 == len(p.config.SensitiveEnv) {
+		return "", nil
+	} else if sensitiveEnvJson, e := json.Marshal(p.config.SensitiveEnv); nil != e {
+		return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	} else {
+		return base64.StdEncoding.EncodeToString(sensitiveEnvJson), nil
+	}
+}
+func (p *Provisioner) hasEnvVars() bool {
+	return (0 < len(p.config.Vars)) || (0 < len(p.config.Env))
+}
+func (p *Provisioner) renderScriptCommand(command string) (string, error) {
+	// The encoded values are rendered into a private copy of the build data so that they never reach later provisioners.
+	if sensitiveEnv, e := p.getSensitiveEnv(); nil != e {
+		return "", e
+	} else if "" == sensitiveEnv {
+		return interpolate.Render(command, &p.config.ctx)
+	} else {
+		data := make(map[string]interface{}, (len(p.generatedData) + 1))
+
+		for key, value := range p.generatedData {
+			data[key] = value
+		}
+
+		data["SensitiveEnv"] = sensitiveEnv
+		ctx := p.config.ctx
+		ctx.Data = data
+
+		return interpolate.Render(command, &ctx)
+	}
+}
+func (p *Provisioner) uploadEnvVars() error {
+	if e := p.communicator.Upload(p.config.RemoteEnvVarPath, strings.NewReader(p.getEnvVarScript()), nil); nil != e {
+		return fmt.Errorf(pwshScriptUploadingErrorFormat, e)
+	}
+
+	return nil
+}
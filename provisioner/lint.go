@@ -0,0 +1,117 @@
+package pwsh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	_ "embed"
+
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+//go:embed lint.ps1
+var lintPs1 string
+
+type lintScript struct {
+	Name string
+	Path string
+}
+
+func (p *Provisioner) getLintScripts(directory string) ([]lintScript, error) {
+	scripts := make([]lintScript, 0, len(p.config.Scripts))
+
+	if nil != p.config.Inline {
+		inlinePath := filepath.Join(directory, "inline.ps1")
+
+		if e := os.WriteFile(inlinePath, []byte(strings.Join(p.config.Inline, "\n")+"\n"), 0644); nil != e {
+			return nil, e
+		}
+
+		scripts = append(scripts, lintScript{Name: "inline", Path: inlinePath})
+	}
+
+	for _, scriptPath := range p.config.Scripts {
+		if absolutePath, e := filepath.Abs(scriptPath); nil != e {
+			return nil, e
+		} else {
+			scripts = append(scripts, lintScript{Name: scriptPath, Path: absolutePath})
+		}
+	}
+
+	return scripts, nil
+}
+func (p *Provisioner) lintScripts() error {
+	pwshPath, e := exec.LookPath(p.config.LintPwshPath)
+
+	if nil != e {
+		log.Printf("[INFO] No host PowerShell installation was found; falling back to the built-in syntax check: %s", e)
+
+		return p.checkScriptSyntax()
+	}
+
+	directory, e := tmp.Dir("pwsh-lint")
+
+	if nil != e {
+		return fmt.Errorf(pwshLintingErrorFormat, e)
+	}
+
+	defer os.RemoveAll(directory)
+
+	lintScriptPath := filepath.Join(directory, "lint.ps1")
+	manifestPath := filepath.Join(directory, "manifest.json")
+
+	if scripts, e := p.getLintScripts(directory); nil != e {
+		return fmt.Errorf(pwshLintingErrorFormat, e)
+	} else if manifest, e := json.Marshal(scripts); nil != e {
+		return fmt.Errorf(pwshLintingErrorFormat, e)
+	} else if e = os.WriteFile(manifestPath, manifest, 0644); nil != e {
+		return fmt.Errorf(pwshLintingErrorFormat, e)
+	} else if e = os.WriteFile(lintScriptPath, []byte(lintPs1), 0644); nil != e {
+		return fmt.Errorf(pwshLintingErrorFormat, e)
+	}
+
+	var stderr bytes.Buffer
+	var stdout bytes.Buffer
+
+	arguments := []string{"-NoLogo", "-NonInteractive", "-NoProfile", "-ExecutionPolicy", "Bypass", "-File", lintScriptPath, "-ManifestPath", manifestPath}
+
+	if p.config.LintAnalyzerIsEnabled {
+		arguments = append(arguments, "-AnalyzerIsEnabled")
+	}
+
+	cmd := exec.Command(pwshPath, arguments...)
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stdout
+
+	if e = cmd.Run(); nil == e {
+		return nil
+	}
+
+	var diagnostics error
+
+	scanner := bufio.NewScanner(&stdout)
+
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); "" != line {
+			diagnostics = packersdk.MultiErrorAppend(diagnostics, errors.New(line))
+		}
+	}
+
+	if nil != diagnostics {
+		return diagnostics
+	} else if message := strings.TrimSpace(stderr.String()); "" != message {
+		return fmt.Errorf(pwshLintingErrorFormat, fmt.Sprintf("%s; %s", e, message))
+	}
+
+	return fmt.Errorf(pwshLintingErrorFormat, e)
+}
@@ -0,0 +1,40 @@
+param (
+    [switch]$AnalyzerIsEnabled,
+    [string]$ManifestPath
+);
+
+$ErrorActionPreference = 'Stop';
+$ProgressPreference = 'SilentlyContinue';
+
+$diagnosticCount = 0;
+$scripts = (Get-Content -Path $ManifestPath -Raw | ConvertFrom-Json);
+
+if ($AnalyzerIsEnabled) {
+    Import-Module -Name 'PSScriptAnalyzer';
+}
+
+foreach ($script in $scripts) {
+    $parseErrors = $null;
+    $tokens = $null;
+
+    [Management.Automation.Language.Parser]::ParseFile($script.Path, [ref]$tokens, [ref]$parseErrors) | Out-Null;
+
+    foreach ($parseError in $parseErrors) {
+        Write-Output ('{0}:{1}:{2}: {3}' -f $script.Name, $parseError.Extent.StartLineNumber, $parseError.Extent.StartColumnNumber, $parseError.Message);
+        $diagnosticCount++;
+    }
+
+    # The analyzer rules are only meaningful once the script parses.
+    if ($AnalyzerIsEnabled -and (0 -eq $parseErrors.Count)) {
+        foreach ($record in (Invoke-ScriptAnalyzer -Path $script.Path -Severity 'Error')) {
+            Write-Output ('{0}:{1}:{2}: {3} ({4})' -f $script.Name, $record.Line, $record.Column, $record.Message, $record.RuleName);
+            $diagnosticCount++;
+        }
+    }
+}
+
+if (0 -lt $diagnosticCount) {
+    exit 1;
+}
+
+exit 0;
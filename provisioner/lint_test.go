@@ -0,0 +1,51 @@
+package pwsh
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestProvisioner_PrepareLint(t *testing.T) {
+	if "windows" == runtime.GOOS {
+		t.Skip("the stand-in pwsh executable is a POSIX shell script")
+	}
+
+	directory := t.TempDir()
+	failingPwshPath := filepath.Join(directory, "pwsh-failing")
+	passingPwshPath := filepath.Join(directory, "pwsh-passing")
+
+	// Stands in for pwsh: reports a parser diagnostic for the inline script, including whether the analyzer was requested.
+	if e := os.WriteFile(failingPwshPath, []byte("#!/bin/sh\ncase \"$*\" in *-AnalyzerIsEnabled*) echo \"inline:1:1: Avoid using aliases (PSAvoidUsingCmdletAliases)\";; *) echo \"inline:1:27: Missing closing '}' in statement block or type definition.\";; esac\nexit 1\n"), 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.WriteFile(passingPwshPath, []byte("#!/bin/sh\nexit 0\n"), 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	testCases := []struct {
+		name               string
+		config             map[string]interface{}
+		expectedDiagnostic string
+	}{
+		{name: "parser", config: map[string]interface{}{"lint_pwsh_path": failingPwshPath}, expectedDiagnostic: "inline:1:27: Missing closing '}'"},
+		{name: "analyzer", config: map[string]interface{}{"lint_analyzer_is_enabled": true, "lint_pwsh_path": failingPwshPath}, expectedDiagnostic: "(PSAvoidUsingCmdletAliases)"},
+		{name: "clean", config: map[string]interface{}{"lint_pwsh_path": passingPwshPath}},
+		{name: "no host pwsh", config: map[string]interface{}{"lint_pwsh_path": filepath.Join(directory, "missing")}, expectedDiagnostic: "inline:1:12: Missing closing '}'."},
+	}
+
+	for _, testCase := range testCases {
+		testCase.config["inline"] = []string{"if ($true) { Write-Output 'Hello, world!';"}
+		testCase.config["lint"] = true
+		e := new(Provisioner).Prepare(testCase.config)
+
+		if "" == testCase.expectedDiagnostic {
+			if nil != e {
+				t.Errorf("%s: unexpected error: %s", testCase.name, e)
+			}
+		} else if (nil == e) || !strings.Contains(e.Error(), testCase.expectedDiagnostic) {
+			t.Errorf("%s: expected diagnostic %q, got %v", testCase.name, testCase.expectedDiagnostic, e)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package pwsh
+
+import (
+	"text/template"
+
+	_ "embed"
+)
+
+//go:embed linux.pwshresolve.sh
+var linuxPwshResolveTemplateSh string
+var linuxPwshResolveTemplate = template.Must(template.New("LinuxPwshResolve").Parse(linuxPwshResolveTemplateSh))
@@ -0,0 +1,2 @@
+pwsh_path="$(command -v '{{.PwshPath}}')" || exit 1
+"$pwsh_path" -NoLogo -NonInteractive -NoProfile -Command '"{{.Prefix}}{0}|{1}|{2}" -f (Get-Process -Id $PID).Path, $PSVersionTable.PSEdition, $PSVersionTable.PSVersion.ToString()'
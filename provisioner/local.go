@@ -0,0 +1,121 @@
+package pwsh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type localCommunicator struct{}
+
+func newLocalCommunicator() *localCommunicator {
+	return &localCommunicator{}
+}
+
+func (c *localCommunicator) Download(src string, writer io.Writer) error {
+	if fileHandle, e := os.Open(src); nil != e {
+		return e
+	} else {
+		defer fileHandle.Close()
+
+		_, e = io.Copy(writer, fileHandle)
+
+		return e
+	}
+}
+func (c *localCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	return copyLocalDirectory(src, dst, exclude)
+}
+func (c *localCommunicator) Start(ctx context.Context, remoteCmd *packersdk.RemoteCmd) error {
+	cmd := newLocalShellCommand(ctx, remoteCmd.Command)
+	cmd.Stderr = remoteCmd.Stderr
+	cmd.Stdin = remoteCmd.Stdin
+	cmd.Stdout = remoteCmd.Stdout
+
+	if e := cmd.Start(); nil != e {
+		return e
+	}
+
+	go func() {
+		exitCode := 0
+
+		if e := cmd.Wait(); nil != e {
+			var exitError *exec.ExitError
+
+			if errors.As(e, &exitError) {
+				exitCode = exitError.ExitCode()
+			} else {
+				exitCode = packersdk.CmdDisconnect
+			}
+		}
+
+		remoteCmd.SetExited(exitCode)
+	}()
+
+	return nil
+}
+func (c *localCommunicator) Upload(dst string, reader io.Reader, fileInfo *os.FileInfo) error {
+	mode := os.FileMode(0644)
+
+	if nil != fileInfo {
+		mode = (*fileInfo).Mode().Perm()
+	}
+
+	if e := os.MkdirAll(filepath.Dir(dst), 0755); nil != e {
+		return e
+	} else if fileHandle, e := os.OpenFile(dst, (os.O_CREATE | os.O_TRUNC | os.O_WRONLY), mode); nil != e {
+		return e
+	} else {
+		if _, e = io.Copy(fileHandle, reader); nil != e {
+			fileHandle.Close()
+
+			return e
+		}
+
+		return fileHandle.Close()
+	}
+}
+func (c *localCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	return copyLocalDirectory(src, dst, exclude)
+}
+
+func copyLocalDirectory(src string, dst string, exclude []string) error {
+	return filepath.Walk(src, func(path string, fileInfo os.FileInfo, e error) error {
+		if nil != e {
+			return e
+		}
+
+		relativePath, e := filepath.Rel(src, path)
+
+		if nil != e {
+			return e
+		}
+
+		for _, pattern := range exclude {
+			if isMatch, _ := filepath.Match(pattern, relativePath); isMatch {
+				if fileInfo.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+		}
+
+		targetPath := filepath.Join(dst, relativePath)
+
+		if fileInfo.IsDir() {
+			return os.MkdirAll(targetPath, 0755)
+		} else if fileHandle, e := os.Open(path); nil != e {
+			return e
+		} else {
+			defer fileHandle.Close()
+
+			return (&localCommunicator{}).Upload(targetPath, fileHandle, &fileInfo)
+		}
+	})
+}
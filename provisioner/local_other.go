@@ -0,0 +1,12 @@
+//go:build !windows
+
+package pwsh
+
+import (
+	"context"
+	"os/exec"
+)
+
+func newLocalShellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
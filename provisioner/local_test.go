@@ -0,0 +1,126 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestLocalCommunicator_Start(t *testing.T) {
+	if "windows" == runtime.GOOS {
+		t.Skip("the command under test is a POSIX shell command")
+	}
+
+	var stderr bytes.Buffer
+	var stdout bytes.Buffer
+
+	remoteCmd := &packersdk.RemoteCmd{
+		Command: `read line; echo "out:$line"; echo err >&2; exit 3`,
+		Stderr:  &stderr,
+		Stdin:   strings.NewReader("value\n"),
+		Stdout:  &stdout,
+	}
+
+	if e := newLocalCommunicator().Start(context.Background(), remoteCmd); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if exitCode := remoteCmd.Wait(); 3 != exitCode {
+		t.Errorf("expected exit code 3, got %d", exitCode)
+	}
+
+	if "out:value\n" != stdout.String() {
+		t.Errorf("unexpected stdout: %q", stdout.String())
+	}
+
+	if "err\n" != stderr.String() {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+}
+func TestLocalCommunicator_UploadAndDownload(t *testing.T) {
+	communicator := newLocalCommunicator()
+	path := filepath.Join(t.TempDir(), "nested", "script.ps1")
+
+	if e := communicator.Upload(path, strings.NewReader("Write-Output 'Hello, world!';\n"), nil); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	var buffer bytes.Buffer
+
+	if e := communicator.Download(path, &buffer); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if "Write-Output 'Hello, world!';\n" != buffer.String() {
+		t.Errorf("unexpected content: %q", buffer.String())
+	}
+}
+func TestProvisioner_ProvisionLocal(t *testing.T) {
+	if "windows" == runtime.GOOS {
+		t.Skip("the stand-in pwsh executable is a POSIX shell script")
+	}
+
+	pwshPath := filepath.Join(t.TempDir(), "pwsh")
+
+	// Stands in for pwsh: answers the installation probe, then reports an output and a non-zero exit code for the script.
+	if e := os.WriteFile(pwshPath, []byte("#!/bin/sh\ncase \"$*\" in *packer-engine*) echo \"packer-engine:$0|Core|7.4.0\";; *) echo 'packer-output:Greeting=Hello'; exit 3;; esac\n"), 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	p := new(Provisioner)
+
+	if e := p.Prepare(map[string]interface{}{
+		"execution_target": "local",
+		"inline":           []string{"Write-Output 'Hello, world!';"},
+		"pwsh_path":        pwshPath,
+		"valid_exit_codes": []int{3},
+	}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	generatedData := make(map[string]interface{})
+
+	if e := p.Provision(context.Background(), packersdk.TestUi(t), nil, generatedData); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if "Hello" != generatedData["PwshOutput_Greeting"] {
+		t.Errorf("expected the script output to be published, got %v", generatedData["PwshOutput_Greeting"])
+	}
+
+	if pwshPath != generatedData["PwshPath"] {
+		t.Errorf("expected the resolved pwsh path to be %s, got %v", pwshPath, generatedData["PwshPath"])
+	}
+}
+func TestProvisioner_PrepareExecutionTarget(t *testing.T) {
+	mismatchedOsType := "windows"
+
+	if "windows" == runtime.GOOS {
+		mismatchedOsType = "ubuntu"
+	}
+
+	testCases := []struct {
+		config          map[string]interface{}
+		isErrorExpected bool
+	}{
+		{map[string]interface{}{"execution_target": "LOCAL"}, false},
+		{map[string]interface{}{"execution_target": "remote"}, false},
+		{map[string]interface{}{"execution_target": "container"}, true},
+		{map[string]interface{}{"execution_target": "local", "reboot_is_enabled": true}, true},
+		{map[string]interface{}{"execution_target": "local", "elevated_user": "packer"}, true},
+		{map[string]interface{}{"execution_target": "local", "os_type": runtime.GOOS}, false},
+		{map[string]interface{}{"execution_target": "local", "os_type": mismatchedOsType}, true},
+	}
+
+	for _, testCase := range testCases {
+		testCase.config["inline"] = []string{"Write-Output 'Hello, world!';"}
+
+		if e := new(Provisioner).Prepare(testCase.config); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%v: expected error: %t, got %v", testCase.config, testCase.isErrorExpected, e)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package pwsh
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+func newLocalShellCommand(ctx context.Context, command string) *exec.Cmd {
+	comSpec := os.Getenv("ComSpec")
+
+	if "" == comSpec {
+		comSpec = filepath.Join(os.Getenv("SystemRoot"), "System32", "cmd.exe")
+	}
+
+	// cmd.exe does not follow the quoting rules that exec.Command applies to its arguments; pass the command line through verbatim instead.
+	cmd := exec.CommandContext(ctx, comSpec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: (`"` + comSpec + `" /S /C "` + command + `"`)}
+
+	return cmd
+}
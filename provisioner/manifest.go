@@ -0,0 +1,100 @@
+package pwsh
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type contentFileInfo struct {
+	os.FileInfo
+
+	size int64
+}
+
+func (i *contentFileInfo) Size() int64 {
+	return i.size
+}
+
+func computeSha256(content []byte) string {
+	checksum := sha256.Sum256(content)
+
+	return hex.EncodeToString(checksum[:])
+}
+func readSha256Manifest(manifestPath string) (map[string]string, error) {
+	if manifestFileHandle, e := os.Open(manifestPath); nil != e {
+		return nil, e
+	} else {
+		defer manifestFileHandle.Close()
+
+		checksums := make(map[string]string)
+		manifestDirectory := filepath.Dir(manifestPath)
+		scanner := bufio.NewScanner(manifestFileHandle)
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+
+			if ("" == line) || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			if fields := strings.SplitN(line, " ", 2); 2 != len(fields) {
+				return nil, fmt.Errorf("malformed line: %s", line)
+			} else {
+				path := strings.TrimPrefix(strings.TrimLeft(fields[1], " "), "*")
+
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(manifestDirectory, path)
+				}
+
+				if path, e = filepath.Abs(path); nil != e {
+					return nil, e
+				}
+
+				checksums[path] = strings.ToLower(fields[0])
+			}
+		}
+
+		return checksums, scanner.Err()
+	}
+}
+func verifySha256Manifest(manifestPath string, scriptPaths []string) (map[string]string, error) {
+	if checksums, e := readSha256Manifest(manifestPath); nil != e {
+		return nil, fmt.Errorf(pwshScriptVerifyingErrorFormat, e)
+	} else {
+		scriptChecksums := make(map[string]string)
+
+		for _, scriptPath := range scriptPaths {
+			if absoluteScriptPath, e := filepath.Abs(scriptPath); nil != e {
+				return nil, fmt.Errorf(pwshScriptVerifyingErrorFormat, e)
+			} else if expectedChecksum, ok := checksums[absoluteScriptPath]; !ok {
+				return nil, fmt.Errorf(pwshScriptVerifyingErrorFormat, fmt.Sprintf("%s is not listed in the manifest", scriptPath))
+			} else if content, e := os.ReadFile(scriptPath); nil != e {
+				return nil, fmt.Errorf(pwshScriptVerifyingErrorFormat, e)
+			} else if expectedChecksum != computeSha256(content) {
+				return nil, fmt.Errorf(pwshScriptVerifyingErrorFormat, fmt.Sprintf("%s does not match its checksum", scriptPath))
+			} else {
+				scriptChecksums[scriptPath] = expectedChecksum
+			}
+		}
+
+		return scriptChecksums, nil
+	}
+}
+
+func (p *Provisioner) readScript(scriptPath string) ([]byte, os.FileInfo, error) {
+	// The content is read once and uploaded as is, so the checksum covers exactly what runs on the remote host.
+	if scriptFileInfo, e := os.Stat(scriptPath); nil != e {
+		return nil, nil, fmt.Errorf(pwshScriptStatingErrorFormat, e)
+	} else if content, e := os.ReadFile(scriptPath); nil != e {
+		return nil, nil, fmt.Errorf(pwshScriptOpeningErrorFormat, e)
+	} else if expectedChecksum, ok := p.scriptChecksums[scriptPath]; ok && (expectedChecksum != computeSha256(content)) {
+		return nil, nil, fmt.Errorf(pwshScriptVerifyingErrorFormat, fmt.Sprintf("%s does not match its checksum", scriptPath))
+	} else {
+		return content, &contentFileInfo{FileInfo: scriptFileInfo, size: int64(len(content))}, nil
+	}
+}
@@ -0,0 +1,101 @@
+package pwsh
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testSha256Manifest(t *testing.T, content string) (string, string) {
+	directory := t.TempDir()
+	manifestPath := filepath.Join(directory, "SHA256SUMS")
+	scriptPath := filepath.Join(directory, "script.ps1")
+
+	if e := os.WriteFile(scriptPath, []byte(content), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.WriteFile(manifestPath, []byte(fmt.Sprintf("%s  script.ps1\n", computeSha256([]byte(content)))), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	return manifestPath, scriptPath
+}
+
+func TestProvisioner_ExecuteScriptCollectionWithManifest(t *testing.T) {
+	testCases := []struct {
+		name            string
+		replacement     string
+		isErrorExpected bool
+	}{
+		{name: "unchanged"},
+		{name: "changed after prepare", replacement: "Write-Output 'Goodbye, world!';\n", isErrorExpected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			manifestPath, scriptPath := testSha256Manifest(t, "Write-Output 'Hello, world!';\n")
+			p, communicator := testProvisioner(t, map[string]interface{}{
+				"script_sha256_manifest": manifestPath,
+				"scripts":                []string{scriptPath},
+			})
+
+			if "" != testCase.replacement {
+				if e := os.WriteFile(scriptPath, []byte(testCase.replacement), 0644); nil != e {
+					t.Fatalf("unexpected error: %s", e)
+				}
+			}
+
+			e := p.executeScriptCollection(context.Background(), []string{scriptPath}, packersdk.TestUi(t))
+
+			if testCase.isErrorExpected != (nil != e) {
+				t.Fatalf("expected error: %t, got %v", testCase.isErrorExpected, e)
+			}
+
+			if testCase.isErrorExpected {
+				if 0 != len(communicator.uploads) {
+					t.Errorf("expected nothing to be uploaded, got %v", communicator.uploads)
+				}
+			} else if "Write-Output 'Hello, world!';\n" != communicator.uploads[p.config.RemotePath] {
+				t.Errorf("expected the verified content to be uploaded, got %q", communicator.uploads[p.config.RemotePath])
+			}
+		})
+	}
+}
+func TestProvisioner_PrepareScriptManifest(t *testing.T) {
+	manifestPath, scriptPath := testSha256Manifest(t, "Write-Output 'Hello, world!';\n")
+	mismatchedManifestPath, mismatchedScriptPath := testSha256Manifest(t, "Write-Output 'Hello, world!';\n")
+	unlistedScriptPath := filepath.Join(t.TempDir(), "unlisted.ps1")
+
+	if e := os.WriteFile(unlistedScriptPath, []byte("Write-Output 'Hello, world!';\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.WriteFile(mismatchedScriptPath, []byte("Write-Output 'Goodbye, world!';\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	testCases := []struct {
+		name          string
+		config        map[string]interface{}
+		expectedError string
+	}{
+		{name: "listed", config: map[string]interface{}{"scripts": []string{scriptPath}}},
+		{name: "unlisted", config: map[string]interface{}{"scripts": []string{unlistedScriptPath}}, expectedError: "is not listed in the manifest"},
+		{name: "mismatched", config: map[string]interface{}{"script_sha256_manifest": mismatchedManifestPath, "scripts": []string{mismatchedScriptPath}}, expectedError: "does not match its checksum"},
+		{name: "template scripts", config: map[string]interface{}{"scripts": []string{scriptPath}, "template_scripts": true}, expectedError: "cannot be combined with 'template_scripts'"},
+	}
+
+	for _, testCase := range testCases {
+		if _, ok := testCase.config["script_sha256_manifest"]; !ok {
+			testCase.config["script_sha256_manifest"] = manifestPath
+		}
+
+		if e := new(Provisioner).Prepare(testCase.config); ("" == testCase.expectedError) != (nil == e) {
+			t.Errorf("%s: expected error %q, got %v", testCase.name, testCase.expectedError, e)
+		} else if (nil != e) && !strings.Contains(e.Error(), testCase.expectedError) {
+			t.Errorf("%s: expected error %q, got %v", testCase.name, testCase.expectedError, e)
+		}
+	}
+}
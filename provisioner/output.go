@@ -0,0 +1,125 @@
+package pwsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type lineCollector struct {
+	lines   []string
+	mutex   sync.Mutex
+	pending string
+	prefix  string
+}
+type pwshErrorRecord struct {
+	Category         string `json:"Category"`
+	Line             int    `json:"Line"`
+	Message          string `json:"Message"`
+	Position         string `json:"Position"`
+	ScriptName       string `json:"ScriptName"`
+	ScriptStackTrace string `json:"ScriptStackTrace"`
+}
+
+func newLineCollector(prefix string) *lineCollector {
+	return &lineCollector{
+		lines:  make([]string, 0),
+		prefix: prefix,
+	}
+}
+func parseErrorRecord(lines []string) *pwshErrorRecord {
+	for i := (len(lines) - 1); 0 <= i; i-- {
+		var errorRecord pwshErrorRecord
+
+		if e := json.Unmarshal([]byte(lines[i]), &errorRecord); nil == e {
+			return &errorRecord
+		}
+	}
+
+	return nil
+}
+func parseOutputs(lines []string) (map[string]string, error) {
+	// Every name is namespaced so that script outputs can never replace the values that the provisioner publishes itself (Path, PwshPath, Vars, ...).
+	outputs := make(map[string]string)
+
+	for _, line := range lines {
+		if pair := strings.SplitN(line, "=", 2); 2 == len(pair) {
+			if name := strings.TrimSpace(pair[0]); !envVarNamePattern.MatchString(name) {
+				return nil, fmt.Errorf("The script output name must start with a letter or underscore and contain only letters, digits or underscores: %q.", name)
+			} else {
+				outputs[pwshOutputKeyPrefix+name] = pair[1]
+			}
+		}
+	}
+
+	return outputs, nil
+}
+
+func (p *Provisioner) writeOutputFile(ui packersdk.Ui) error {
+	// Packer does not hand provisioner data back to core, so the published values are also written on the build host for later provisioners and post-processors.
+	if "" == p.config.OutputFile {
+		return nil
+	}
+
+	outputs := make(map[string]interface{})
+
+	for name, value := range p.generatedData {
+		if strings.HasPrefix(name, pwshOutputKeyPrefix) || ("PwshEdition" == name) || ("PwshPath" == name) || ("PwshVersion" == name) {
+			outputs[name] = value
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Writing PowerShell outputs; local path: %s", p.config.OutputFile))
+
+	if outputJson, e := json.MarshalIndent(outputs, "", "  "); nil != e {
+		return fmt.Errorf(pwshOutputWritingErrorFormat, e)
+	} else if e = os.MkdirAll(filepath.Dir(p.config.OutputFile), 0755); nil != e {
+		return fmt.Errorf(pwshOutputWritingErrorFormat, e)
+	} else if e = os.WriteFile(p.config.OutputFile, append(outputJson, '\n'), 0644); nil != e {
+		return fmt.Errorf(pwshOutputWritingErrorFormat, e)
+	}
+
+	return nil
+}
+
+func (c *lineCollector) Flush() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if "" != c.pending {
+		c.collectLine(c.pending)
+		c.pending = ""
+	}
+
+	return c.lines
+}
+func (c *lineCollector) Write(p []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	lines := strings.Split((c.pending + string(p)), "\n")
+	c.pending = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		c.collectLine(line)
+	}
+
+	return len(p), nil
+}
+
+func (c *lineCollector) collectLine(line string) {
+	line = strings.TrimRight(line, "\r")
+
+	if ("" != c.prefix) && strings.HasPrefix(line, c.prefix) {
+		c.lines = append(c.lines, strings.TrimPrefix(line, c.prefix))
+	}
+}
+
+func (r *pwshErrorRecord) Error() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", r.ScriptName, r.Line, r.Message, r.Category)
+}
@@ -0,0 +1,207 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+	"github.com/hashicorp/packer-plugin-sdk/uuid"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+const (
+	pesterDownloadingErrorFormat = "Error downloading Pester test results: %s."
+	pesterTestingErrorFormat     = "Error running Pester tests: %s."
+	pesterUploadingErrorFormat   = "Error uploading Pester tests: %s."
+)
+
+//go:embed pester.ps1
+var pesterTemplatePs1 string
+var pesterTemplate = template.Must(template.New("Pester").Parse(pesterTemplatePs1))
+
+type PesterConfig struct {
+	Config `mapstructure:",squash"`
+
+	PesterVersion  string   `mapstructure:"pester_version"`
+	RemoteTestPath string   `mapstructure:"remote_test_path"`
+	ResultFormat   string   `mapstructure:"result_format"`
+	ResultPath     string   `mapstructure:"result_path"`
+	Tests          []string `mapstructure:"tests"`
+}
+type PesterProvisioner struct {
+	config      PesterConfig
+	provisioner Provisioner
+}
+
+func (p *PesterProvisioner) ConfigSpec() hcldec.ObjectSpec {
+	return p.config.FlatMapstructure().HCL2Spec()
+}
+func (p *PesterProvisioner) Prepare(raws ...interface{}) error {
+	if e := decodeConfig(&p.config, &p.config.ctx, raws...); nil != e {
+		return e
+	}
+
+	var e error
+
+	if "" == p.config.PesterVersion {
+		p.config.PesterVersion = "5.0.0"
+	}
+
+	if "" == p.config.ResultFormat {
+		p.config.ResultFormat = "NUnitXml"
+	}
+
+	if "" == p.config.ResultPath {
+		p.config.ResultPath = "pester-results.xml"
+	}
+
+	if (nil != p.config.Inline) || (0 < len(p.config.Scripts)) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'inline' and 'scripts' parameters are not supported; the Pester runner script is generated."))
+	}
+
+	if strings.EqualFold("JUnitXml", p.config.ResultFormat) {
+		p.config.ResultFormat = "JUnitXml"
+	} else if strings.EqualFold("NUnitXml", p.config.ResultFormat) {
+		p.config.ResultFormat = "NUnitXml"
+	} else {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'result_format' parameter must be one of 'JUnitXml' or 'NUnitXml': %s.", p.config.ResultFormat))
+	}
+
+	if 0 == len(p.config.Tests) {
+		e = packersdk.MultiErrorAppend(e, errors.New("At least one path must be specified in 'tests'."))
+	}
+
+	for _, test := range p.config.Tests {
+		if _, err := os.Stat(test); nil != err {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'tests' path could not be read: %s.", err))
+		}
+	}
+
+	if strings.Contains(p.config.RemoteTestPath, "'") {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'remote_test_path' parameter must not contain single quotes."))
+	}
+
+	if strings.EqualFold("AllSigned", p.config.ExecutionPolicy) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'AllSigned' execution policy is not supported; the Pester runner script is generated and cannot be signed."))
+	}
+
+	p.provisioner.config = p.config.Config
+
+	if err := p.provisioner.prepareConfig(); nil != err {
+		e = packersdk.MultiErrorAppend(e, err)
+	}
+
+	if nil != e {
+		return e
+	}
+
+	if "" == p.config.RemoteTestPath {
+		p.config.RemoteTestPath = fmt.Sprintf("%s/packer-pwsh-pester-%s", path.Dir(p.provisioner.config.RemotePath), uuid.TimeOrderedUUID())
+	}
+
+	var buffer bytes.Buffer
+
+	if e = pesterTemplate.Execute(&buffer, map[string]string{
+		"OutputPrefix":  strings.ReplaceAll(p.provisioner.config.OutputPrefix, "'", "''"),
+		"PesterVersion": strings.ReplaceAll(p.config.PesterVersion, "'", "''"),
+		"ResultFormat":  p.config.ResultFormat,
+		"ResultPath":    p.getRemoteResultPath(),
+		"TestPath":      p.config.RemoteTestPath,
+	}); nil != e {
+		return fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	}
+
+	p.provisioner.config.Inline = []string{buffer.String()}
+
+	return nil
+}
+func (p *PesterProvisioner) Provision(ctx context.Context, ui packersdk.Ui, communicator packersdk.Communicator, generatedData map[string]interface{}) error {
+	if "local" == p.provisioner.config.ExecutionTarget {
+		communicator = newLocalCommunicator()
+	}
+
+	if e := p.uploadTests(communicator, ui); nil != e {
+		return e
+	}
+
+	if e := p.provisioner.Provision(ctx, ui, communicator, generatedData); nil != e {
+		return e
+	}
+
+	if e := p.downloadResults(communicator, ui); nil != e {
+		return e
+	}
+
+	ui.Say(fmt.Sprintf(
+		"Pester test summary; total: %v; passed: %v; failed: %v; skipped: %v",
+		generatedData[pwshOutputKeyPrefix+"PesterTotalCount"],
+		generatedData[pwshOutputKeyPrefix+"PesterPassedCount"],
+		generatedData[pwshOutputKeyPrefix+"PesterFailedCount"],
+		generatedData[pwshOutputKeyPrefix+"PesterSkippedCount"],
+	))
+
+	// The run result also covers container and block failures (e.g. a test file that does not parse), which are not counted as failed tests.
+	if totalCount, e := strconv.Atoi(fmt.Sprint(generatedData[pwshOutputKeyPrefix+"PesterTotalCount"])); nil != e {
+		return fmt.Errorf(pesterTestingErrorFormat, "the runner did not report a result")
+	} else if 0 == totalCount {
+		return fmt.Errorf(pesterTestingErrorFormat, "no tests were discovered")
+	} else if result := fmt.Sprint(generatedData[pwshOutputKeyPrefix+"PesterResult"]); "Passed" != result {
+		return fmt.Errorf(pesterTestingErrorFormat, fmt.Sprintf("the run result was '%s' with %v failed test(s)", result, generatedData[pwshOutputKeyPrefix+"PesterFailedCount"]))
+	}
+
+	return nil
+}
+
+func (p *PesterProvisioner) downloadResults(communicator packersdk.Communicator, ui packersdk.Ui) error {
+	ui.Say(fmt.Sprintf("Downloading Pester test results; local path: %s", p.config.ResultPath))
+
+	if e := os.MkdirAll(filepath.Dir(p.config.ResultPath), 0755); nil != e {
+		return fmt.Errorf(pesterDownloadingErrorFormat, e)
+	} else if resultFileHandle, e := os.Create(p.config.ResultPath); nil != e {
+		return fmt.Errorf(pesterDownloadingErrorFormat, e)
+	} else {
+		defer resultFileHandle.Close()
+
+		if e = communicator.Download(p.getRemoteResultPath(), resultFileHandle); nil != e {
+			return fmt.Errorf(pesterDownloadingErrorFormat, e)
+		}
+
+		return nil
+	}
+}
+func (p *PesterProvisioner) getRemoteResultPath() string {
+	return (p.config.RemoteTestPath + ".xml")
+}
+func (p *PesterProvisioner) uploadTests(communicator packersdk.Communicator, ui packersdk.Ui) error {
+	ui.Say(fmt.Sprintf("Uploading Pester tests; remote path: %s", p.config.RemoteTestPath))
+
+	if stagingPath, e := tmp.Dir("pwsh-pester"); nil != e {
+		return fmt.Errorf(pesterUploadingErrorFormat, e)
+	} else {
+		defer os.RemoveAll(stagingPath)
+
+		for _, test := range p.config.Tests {
+			if e = copyLocalDirectory(test, filepath.Join(stagingPath, filepath.Base(test)), nil); nil != e {
+				return fmt.Errorf(pesterUploadingErrorFormat, e)
+			}
+		}
+
+		if e = communicator.UploadDir(p.config.RemoteTestPath, (stagingPath + string(os.PathSeparator)), nil); nil != e {
+			return fmt.Errorf(pesterUploadingErrorFormat, e)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,35 @@
+$ErrorActionPreference = 'Stop';
+$ProgressPreference = 'SilentlyContinue';
+
+$minimumVersion = [version]'{{.PesterVersion}}';
+
+if (-not (Get-Module -ListAvailable -Name 'Pester' | Where-Object { $_.Version -ge $minimumVersion; })) {
+    Write-Output ('Installing Pester; minimum version: {0}' -f $minimumVersion);
+
+    if ('Core' -ne $PSVersionTable.PSEdition) {
+        [Net.ServicePointManager]::SecurityProtocol = ([Net.ServicePointManager]::SecurityProtocol -bor [Net.SecurityProtocolType]::Tls12);
+        Install-PackageProvider -Force -MinimumVersion '2.8.5.201' -Name 'NuGet' | Out-Null;
+    }
+
+    Install-Module -AllowClobber -Force -MinimumVersion $minimumVersion -Name 'Pester' -Repository 'PSGallery' -Scope 'CurrentUser' -SkipPublisherCheck;
+}
+
+Import-Module -MinimumVersion $minimumVersion -Name 'Pester';
+
+$configuration = New-PesterConfiguration;
+$configuration.Output.Verbosity = 'Detailed';
+$configuration.Run.PassThru = $true;
+$configuration.Run.Path = '{{.TestPath}}';
+$configuration.TestResult.Enabled = $true;
+$configuration.TestResult.OutputFormat = '{{.ResultFormat}}';
+$configuration.TestResult.OutputPath = '{{.ResultPath}}';
+
+$result = Invoke-Pester -Configuration $configuration;
+
+Write-Output ('{{.OutputPrefix}}PesterFailedCount={0}' -f $result.FailedCount);
+Write-Output ('{{.OutputPrefix}}PesterPassedCount={0}' -f $result.PassedCount);
+Write-Output ('{{.OutputPrefix}}PesterResult={0}' -f $result.Result);
+Write-Output ('{{.OutputPrefix}}PesterSkippedCount={0}' -f $result.SkippedCount);
+Write-Output ('{{.OutputPrefix}}PesterTotalCount={0}' -f $result.TotalCount);
+
+exit 0;
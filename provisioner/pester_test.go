@@ -0,0 +1,111 @@
+package pwsh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testPesterConfig(t *testing.T) map[string]interface{} {
+	directory := t.TempDir()
+	testPath := filepath.Join(directory, "example.Tests.ps1")
+
+	if e := os.WriteFile(testPath, []byte("Describe 'Example' { It 'passes' { $true | Should -BeTrue; }; };\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	return map[string]interface{}{
+		"os_type":     "ubuntu",
+		"result_path": filepath.Join(directory, "results", "pester.xml"),
+		"tests":       []string{testPath},
+	}
+}
+
+func TestPesterProvisioner_Prepare(t *testing.T) {
+	testCases := []struct {
+		name            string
+		overrides       map[string]interface{}
+		isErrorExpected bool
+	}{
+		{name: "defaults"},
+		{name: "junit", overrides: map[string]interface{}{"result_format": "junitxml"}},
+		{name: "all signed", overrides: map[string]interface{}{"execution_policy": "AllSigned"}, isErrorExpected: true},
+		{name: "inline", overrides: map[string]interface{}{"inline": []string{"Write-Output 'Hello, world!';"}}, isErrorExpected: true},
+		{name: "invalid result format", overrides: map[string]interface{}{"result_format": "Html"}, isErrorExpected: true},
+		{name: "missing tests", overrides: map[string]interface{}{"tests": []string{"missing.Tests.ps1"}}, isErrorExpected: true},
+		{name: "no tests", overrides: map[string]interface{}{"tests": []string{}}, isErrorExpected: true},
+	}
+
+	for _, testCase := range testCases {
+		config := testPesterConfig(t)
+
+		for key, value := range testCase.overrides {
+			config[key] = value
+		}
+
+		p := new(PesterProvisioner)
+
+		if e := p.Prepare(config); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%s: expected error: %t, got %v", testCase.name, testCase.isErrorExpected, e)
+		}
+	}
+
+	p := new(PesterProvisioner)
+
+	if e := p.Prepare(testPesterConfig(t)); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if !strings.HasPrefix(p.config.RemoteTestPath, "/tmp/packer-pwsh-pester-") {
+		t.Errorf("expected a default remote test path, got %q", p.config.RemoteTestPath)
+	} else if script := p.provisioner.config.Inline[0]; !strings.Contains(script, p.config.RemoteTestPath) || !strings.Contains(script, "'NUnitXml'") {
+		t.Errorf("expected the runner to reference the remote test path and result format, got %q", script)
+	}
+}
+func TestPesterProvisioner_Provision(t *testing.T) {
+	testCases := []struct {
+		name            string
+		stdout          string
+		isErrorExpected bool
+	}{
+		{name: "passed", stdout: "packer-output:PesterFailedCount=0\npacker-output:PesterResult=Passed\npacker-output:PesterTotalCount=3\n"},
+		{name: "failed", stdout: "packer-output:PesterFailedCount=2\npacker-output:PesterResult=Failed\npacker-output:PesterTotalCount=3\n", isErrorExpected: true},
+		{name: "container failed", stdout: "packer-output:PesterFailedCount=0\npacker-output:PesterResult=Failed\npacker-output:PesterTotalCount=3\n", isErrorExpected: true},
+		{name: "no tests", stdout: "packer-output:PesterFailedCount=0\npacker-output:PesterResult=Passed\npacker-output:PesterTotalCount=0\n", isErrorExpected: true},
+		{name: "no result", stdout: "Hello, world!\n", isErrorExpected: true},
+	}
+
+	for _, testCase := range testCases {
+		config := testPesterConfig(t)
+		p := new(PesterProvisioner)
+
+		if e := p.Prepare(config); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.name, e)
+		}
+
+		communicator := newFakeCommunicator(
+			&fakeCommandRule{command: "base64 -d | sh", responses: []fakeCommandResponse{{stdout: (pwshResolvePrefix + "/usr/bin/pwsh|Core|7.4.0\n")}}},
+			&fakeCommandRule{responses: []fakeCommandResponse{{stdout: testCase.stdout}}},
+		)
+		communicator.files[p.getRemoteResultPath()] = "<test-results />"
+		generatedData := make(map[string]interface{})
+
+		e := p.Provision(context.Background(), packersdk.TestUi(t), communicator, generatedData)
+
+		if testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%s: expected error: %t, got %v", testCase.name, testCase.isErrorExpected, e)
+		}
+
+		if _, ok := communicator.directories[p.config.RemoteTestPath]; !ok {
+			t.Errorf("%s: expected the tests to be uploaded to %q", testCase.name, p.config.RemoteTestPath)
+		}
+
+		if content, e := os.ReadFile(config["result_path"].(string)); nil != e {
+			t.Errorf("%s: unexpected error: %s", testCase.name, e)
+		} else if "<test-results />" != string(content) {
+			t.Errorf("%s: expected the result file to be downloaded, got %q", testCase.name, string(content))
+		}
+	}
+}
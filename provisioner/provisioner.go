@@ -1,4 +1,4 @@
-//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,DscConfig,Download,PesterConfig,ScriptOverride
 
 package pwsh
 
@@ -6,16 +6,19 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 	"text/template"
 	"time"
+	"unicode/utf16"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
-	"github.com/hashicorp/packer-plugin-sdk/guestexec"
 	"github.com/hashicorp/packer-plugin-sdk/retry"
 	"github.com/hashicorp/packer-plugin-sdk/shell"
 	"github.com/hashicorp/packer-plugin-sdk/template/config"
@@ -27,43 +30,127 @@ import (
 )
 
 const (
-	defaultStartTimeout            = (7 * time.Minute)
-	defaultTries                   = 1
-	pwshScriptClosingErrorFormat   = "Error closing PowerShell script: %s."
-	pwshScriptOpeningErrorFormat   = "Error opening PowerShell script: %s."
-	pwshScriptPreparingErrorFormat = "Error preparing PowerShell script: %s."
-	pwshScriptRemovingErrorFormat  = "Error removing PowerShell script: %s."
-	pwshScriptStatingErrorFormat   = "Error stating PowerShell script: %s."
-	pwshScriptUploadingErrorFormat = "Error uploading PowerShell script: %s."
+	defaultStartTimeout                  = (7 * time.Minute)
+	defaultTries                         = 1
+	pwshDownloadPrefix                   = "packer-download:"
+	pwshDownloadingErrorFormat           = "Error downloading artifact: %s."
+	pwshErrorRecordPrefix                = "packer-error:"
+	pwshLintingErrorFormat               = "Error linting PowerShell scripts: %s."
+	pwshOutputKeyPrefix                  = "PwshOutput_"
+	pwshOutputWritingErrorFormat         = "Error writing PowerShell outputs: %s."
+	pwshResolvePrefix                    = "packer-engine:"
+	pwshResolvingErrorFormat             = "Error resolving PowerShell installation: %s."
+	pwshScriptExecutingErrorFormat       = "Error executing PowerShell script: %s."
+	pwshScriptLoggingErrorFormat         = "Error creating PowerShell script log: %s."
+	pwshScriptOpeningErrorFormat         = "Error opening PowerShell script: %s."
+	pwshScriptPreparingErrorFormat       = "Error preparing PowerShell script: %s."
+	pwshScriptRemovingErrorFormat        = "Error removing PowerShell script: %s."
+	pwshScriptStatingErrorFormat         = "Error stating PowerShell script: %s."
+	pwshScriptTemplatingErrorFormat      = "Error rendering PowerShell script template: %s; %s."
+	pwshScriptTimeoutErrorFormat         = "Error executing PowerShell script: %s; timed out after %s."
+	pwshScriptUploadingErrorFormat       = "Error uploading PowerShell script: %s."
+	pwshScriptVerifyingErrorFormat       = "Error verifying PowerShell script: %s."
+	pwshTranscriptDownloadingErrorFormat = "Error downloading PowerShell transcript: %s."
+	pwshUpdatingErrorFormat              = "Error updating PowerShell installation: %s."
+	workingDirectoryInvalidCharacters    = "\"$'`"
+)
+
+var (
+	envVarNamePattern            = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	logFileNameInvalidCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	rebootPollInterval           = (13 * time.Second)
+	userNamePattern              = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*\$?$`)
 )
 
 type Config struct {
 	shell.Provisioner               `mapstructure:",squash"`
 	shell.ProvisionerRemoteSpecific `mapstructure:",squash"`
 
-	ElevatedEnvVarFormat         string `mapstructure:"elevated_env_var_format"`
-	ElevatedExecuteCommand       string `mapstructure:"elevated_execute_command"`
-	ElevatedPassword             string `mapstructure:"elevated_password"`
-	ElevatedUser                 string `mapstructure:"elevated_user"`
-	OsType                       string `mapstructure:"os_type"`
-	PwshAutoUpdateCommand        string `mapstructure:"pwsh_autoupdate_command"`
-	PwshAutoUpdateExecuteCommand string `mapstructure:"pwsh_autoupdate_execute_command"`
-	PwshAutoUpdateIsEnabled      bool   `mapstructure:"pwsh_autoupdate_is_enabled"`
-	RebootCompleteCommand        string `mapstructure:"reboot_complete_command"`
-	RebootInitiateCommand        string `mapstructure:"reboot_initiate_command"`
-	RebootIsEnabled              bool   `mapstructure:"reboot_is_enabled"`
-	RebootPendingCommand         string `mapstructure:"reboot_pending_command"`
-	RebootProgressCommand        string `mapstructure:"reboot_progress_command"`
-	RebootValidateCommand        string `mapstructure:"reboot_validate_command"`
-	RemoteEnvVarPath             string `mapstructure:"remote_env_var_path"`
-	RemotePwshAutoUpdatePath     string `mapstructure:"remote_pwsh_autoupdate_path"`
+	Downloads                    []Download        `mapstructure:"download"`
+	ElevatedEnvVarFormat         string            `mapstructure:"elevated_env_var_format"`
+	ElevatedExecuteCommand       string            `mapstructure:"elevated_execute_command"`
+	ElevatedPassword             string            `mapstructure:"elevated_password"`
+	ElevatedUser                 string            `mapstructure:"elevated_user"`
+	ElevationMethod              string            `mapstructure:"elevation_method"`
+	ErrorActionPreference        string            `mapstructure:"error_action_preference"`
+	ExecutionPolicy              string            `mapstructure:"execution_policy"`
+	ExecutionTarget              string            `mapstructure:"execution_target"`
+	ExtraArguments               []string          `mapstructure:"extra_arguments"`
+	Lint                         bool              `mapstructure:"lint"`
+	LintAnalyzerIsEnabled        bool              `mapstructure:"lint_analyzer_is_enabled"`
+	LintPwshPath                 string            `mapstructure:"lint_pwsh_path"`
+	LogDir                       string            `mapstructure:"log_dir"`
+	NoProfile                    config.Trilean    `mapstructure:"no_profile"`
+	OsType                       string            `mapstructure:"os_type"`
+	OutputFile                   string            `mapstructure:"output_file"`
+	OutputPrefix                 string            `mapstructure:"output_prefix"`
+	PowershellEdition            string            `mapstructure:"powershell_edition"`
+	ProgressPreference           string            `mapstructure:"progress_preference"`
+	PwshAutoUpdateCommand        string            `mapstructure:"pwsh_autoupdate_command"`
+	PwshAutoUpdateExecuteCommand string            `mapstructure:"pwsh_autoupdate_execute_command"`
+	PwshAutoUpdateIsEnabled      bool              `mapstructure:"pwsh_autoupdate_is_enabled"`
+	PwshPath                     string            `mapstructure:"pwsh_path"`
+	RebootCompleteCommand        string            `mapstructure:"reboot_complete_command"`
+	RebootInitiateCommand        string            `mapstructure:"reboot_initiate_command"`
+	RebootIsEnabled              bool              `mapstructure:"reboot_is_enabled"`
+	RebootPendingCommand         string            `mapstructure:"reboot_pending_command"`
+	RebootProgressCommand        string            `mapstructure:"reboot_progress_command"`
+	RebootValidateCommand        string            `mapstructure:"reboot_validate_command"`
+	RemoteEnvVarPath             string            `mapstructure:"remote_env_var_path"`
+	RemotePwshAutoUpdatePath     string            `mapstructure:"remote_pwsh_autoupdate_path"`
+	RemoteTranscriptPath         string            `mapstructure:"remote_transcript_path"`
+	RunAsExecuteCommand          string            `mapstructure:"run_as_execute_command"`
+	RunAsUser                    string            `mapstructure:"run_as_user"`
+	SensitiveEnv                 map[string]string `mapstructure:"sensitive_env"`
+	ScriptKillCommand            string            `mapstructure:"script_kill_command"`
+	ScriptManifestPath           string            `mapstructure:"script_sha256_manifest"`
+	ScriptOverrides              []ScriptOverride  `mapstructure:"script_override"`
+	ScriptTimeout                time.Duration     `mapstructure:"script_timeout"`
+	TemplateDelimiters           []string          `mapstructure:"template_delimiters"`
+	TemplateScripts              bool              `mapstructure:"template_scripts"`
+	TranscriptDir                string            `mapstructure:"transcript_dir"`
+	UseFileParameter             bool              `mapstructure:"use_file_parameter"`
+	WorkingDirectory             string            `mapstructure:"working_directory"`
 
 	ctx interpolate.Context
 }
+type Download struct {
+	Destination string `mapstructure:"destination"`
+	Source      string `mapstructure:"source"`
+}
+type ScriptOverride struct {
+	Path             string        `mapstructure:"path"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	WorkingDirectory string        `mapstructure:"working_directory"`
+}
 type Provisioner struct {
-	config        Config
-	communicator  packersdk.Communicator
-	generatedData map[string]interface{}
+	config            Config
+	communicator      packersdk.Communicator
+	generatedData     map[string]interface{}
+	remoteScriptPaths map[string]string
+	scriptChecksums   map[string]string
+}
+
+func decodeConfig(target interface{}, ctx *interpolate.Context, raws ...interface{}) error {
+	return config.Decode(
+		target,
+		&config.DecodeOpts{
+			DecodeHooks:        config.DefaultDecodeHookFuncs,
+			Interpolate:        true,
+			InterpolateContext: ctx,
+			InterpolateFilter: &interpolate.RenderFilter{
+				Exclude: []string{
+					"elevated_execute_command",
+					"run_as_execute_command",
+					"execute_command",
+					"inline",
+					"script_kill_command",
+				},
+			},
+			PluginType: "pwsh",
+		},
+		raws...,
+	)
 }
 
 func (p *Provisioner) Communicator() packersdk.Communicator {
@@ -71,7 +158,7 @@ func (p *Provisioner) Communicator() packersdk.Communicator {
 }
 func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
 func (p *Provisioner) ElevatedExecuteCommand() string {
-	return fmt.Sprintf(p.config.ElevatedExecuteCommand, p.config.ExecuteCommand)
+	return p.wrapElevatedCommand(p.config.ExecuteCommand)
 }
 func (p *Provisioner) ElevatedPassword() string {
 	elevatedPassword, _ := interpolate.Render(p.config.ElevatedPassword, &p.config.ctx)
@@ -82,266 +169,647 @@ func (p *Provisioner) ElevatedUser() string {
 	return p.config.ElevatedUser
 }
 func (p *Provisioner) Prepare(raws ...interface{}) error {
-	if e := config.Decode(
-		&p.config,
-		&config.DecodeOpts{
-			DecodeHooks:        append(config.DefaultDecodeHookFuncs),
-			Interpolate:        true,
-			InterpolateContext: &p.config.ctx,
-			InterpolateFilter: &interpolate.RenderFilter{
-				Exclude: []string{
-					"elevated_execute_command",
-					"execute_command",
-				},
-			},
-			PluginType: "pwsh",
-		},
-		raws...,
-	); nil != e {
+	if e := decodeConfig(&p.config, &p.config.ctx, raws...); nil != e {
 		return e
-	} else {
-		defaultElevatedUser := p.config.ElevatedUser
+	}
 
-		if "" == defaultElevatedUser {
-			defaultElevatedUser = "packer"
-		}
+	var e error
 
-		defaultElevatedExecuteCommand := fmt.Sprintf(`echo "%s" | sudo -S sh -e -c '%%s'`, defaultElevatedUser)
-		defaultExecuteCommand := `chmod +x {{.Path}} && pwsh -ExecutionPolicy "Bypass" -NoLogo -NonInteractive -NoProfile -Command "`
-		defaultExecuteCommand += `if (Test-Path variable:global:ErrorActionPreference) { Set-Variable -Name variable:global:ErrorActionPreference -Value ([Management.Automation.ActionPreference]::Stop); } `
-		defaultExecuteCommand += `if (Test-Path variable:global:ProgressPreference) { Set-Variable -Name variable:global:ProgressPreference -Value ([Management.Automation.ActionPreference]::SilentlyContinue); } `
-		defaultExecuteCommand += `&'{{.Path}}'; exit $LastExitCode;"`
-		defaultPwshAutoUpdateExecuteCommand := "chmod +x {{.Path}} && {{.Path}}"
-		defaultPwshAutoUpdateScriptExtension := `sh`
-		defaultRebootCompleteCommand := ""
-		defaultRebootInitiateCommand := ""
-		defaultRebootProgressCommand := ""
-		defaultRebootValidateCommand := `pwsh -ExecutionPolicy "Bypass" -NoLogo -NonInteractive -NoProfile -Command "exit 0;"`
-		defaultRemotePathFormat := `%s/packer-pwsh-%s-%%s.%s`
-		defaultRemoteScriptDirectoryPath := `/tmp`
+	if err := p.prepareConfig(); nil != err {
+		e = packersdk.MultiErrorAppend(e, err)
+	}
 
-		var defaultPwshAutoUpdateTemplate *template.Template
-		var defaultRebootPendingTemplate *template.Template
+	// The Pester and DSC provisioners generate their own runner script, so only user-facing configurations must name one.
+	if (nil == p.config.Inline) && (0 == len(p.config.Scripts)) {
+		e = packersdk.MultiErrorAppend(e, errors.New("Either a script file or an inline script must be specified."))
+	} else if (nil != p.config.Inline) && (0 < len(p.config.Scripts)) {
+		e = packersdk.MultiErrorAppend(e, errors.New("Only a script file or an inline script can be specified, not both."))
+	}
 
-		p.config.OsType = strings.ToLower(p.config.OsType)
+	return e
+}
+func (p *Provisioner) Provision(context context.Context, ui packersdk.Ui, communicator packersdk.Communicator, generatedData map[string]interface{}) error {
+	p.communicator = communicator
 
-		switch p.config.OsType {
-		case "debian":
-			defaultPwshAutoUpdateTemplate = debianPwshAutoUpdateTemplate
+	if "local" == p.config.ExecutionTarget {
+		p.communicator = newLocalCommunicator()
+	}
 
-			break
-		case "ubuntu":
-			defaultPwshAutoUpdateTemplate = ubuntuPwshAutoUpdateTemplate
+	p.config.ctx.Data = generatedData
+	p.generatedData = generatedData
+	p.generatedData["Vars"] = p.config.RemoteEnvVarPath
+	p.generatedData["WorkingDirectory"] = p.config.WorkingDirectory
+	p.remoteScriptPaths = make(map[string]string)
 
-			break
-		case "windows":
-			defaultElevatedExecuteCommand = `%s`
-			defaultExecuteCommand = `FOR /F "tokens=* USEBACKQ" %F IN (` + "`where pwsh /R \"%PROGRAMFILES%\\PowerShell\" ^2^>nul ^|^| where powershell`" + `) DO ("%F" -ExecutionPolicy "Bypass" -NoLogo -NonInteractive -NoProfile -Command "`
-			defaultExecuteCommand += `if (Test-Path variable:global:ErrorActionPreference) { Set-Variable -Name variable:global:ErrorActionPreference -Value ([Management.Automation.ActionPreference]::Stop); } `
-			defaultExecuteCommand += `if (Test-Path variable:global:ProgressPreference) { Set-Variable -Name variable:global:ProgressPreference -Value ([Management.Automation.ActionPreference]::SilentlyContinue); } `
-			defaultExecuteCommand += `&'{{.Path}}'; exit $LastExitCode;")`
-			defaultPwshAutoUpdateExecuteCommand = defaultExecuteCommand
-			defaultPwshAutoUpdateScriptExtension = `ps1`
-			defaultPwshAutoUpdateTemplate = windowsPwshAutoUpdateTemplate
-			defaultRebootCompleteCommand = `shutdown /a`
-			defaultRebootInitiateCommand = `shutdown /r /f /t 0 /c "packer reboot"`
-			defaultRebootPendingTemplate = windowsRebootPendingTemplate
-			defaultRebootProgressCommand = `shutdown /r /f /t 60 /c "packer reboot test"`
-			defaultRemoteScriptDirectoryPath = `C:/Windows/Temp`
+	packersdk.LogSecretFilter.Set(p.getSensitiveValues()...)
 
-			break
-		default:
-			defaultPwshAutoUpdateTemplate = nil
-			defaultRebootPendingTemplate = nil
+	if e := p.provision(context, newRedactUi(ui)); nil != e {
+		return errors.New(packersdk.LogSecretFilter.FilterString(e.Error()))
+	}
 
-			break
-		}
+	return nil
+}
+
+func (p *Provisioner) createScriptLogFile(directory string, index int, scriptPath string, extension string) (*os.File, error) {
+	scriptName := "inline"
+
+	if p.isScriptPath(scriptPath) {
+		scriptName = filepath.Base(scriptPath)
+	}
+
+	logFileName := fmt.Sprintf("%s-%03d-%s.%s", p.config.PackerBuildName, index, scriptName, extension)
+	logFileName = logFileNameInvalidCharacters.ReplaceAllString(strings.TrimPrefix(logFileName, "-"), "_")
+
+	if e := os.MkdirAll(directory, 0755); nil != e {
+		return nil, fmt.Errorf(pwshScriptLoggingErrorFormat, e)
+	} else if logFileHandle, e := os.Create(filepath.Join(directory, logFileName)); nil != e {
+		return nil, fmt.Errorf(pwshScriptLoggingErrorFormat, e)
+	} else {
+		return logFileHandle, nil
+	}
+}
+func (p *Provisioner) executeScriptCollection(context context.Context, scriptPaths []string, ui packersdk.Ui) error {
+	remotePath := p.config.RemotePath
+	p.generatedData["Path"] = remotePath
 
-		var formatRemotePath = func(extension string, suffix string) string {
-			return fmt.Sprintf(defaultRemotePathFormat, defaultRemoteScriptDirectoryPath, suffix, extension)
+	if p.hasEnvVars() {
+		if e := p.uploadEnvVars(); nil != e {
+			return e
 		}
 
-		if "" == p.config.ElevatedExecuteCommand {
-			p.config.ElevatedExecuteCommand = defaultElevatedExecuteCommand
+		defer func() {
+			if e := p.removeRemoteFiles(ui, p.config.RemoteEnvVarPath); nil != e {
+				ui.Error(fmt.Sprintf("Failed to remove environment variable file: %s", e))
+			}
+		}()
+	}
+
+	for index, scriptPath := range scriptPaths {
+		if e := p.executeUserScript(context, index, remotePath, scriptPath, ui); nil != e {
+			return e
 		}
+	}
+
+	return nil
+}
+func (p *Provisioner) executeUserScript(context context.Context, index int, remotePath string, scriptPath string, ui packersdk.Ui) error {
+	if "" != p.config.LogDir {
+		if logFileHandle, e := p.createScriptLogFile(p.config.LogDir, index, scriptPath, "log"); nil != e {
+			return e
+		} else {
+			defer logFileHandle.Close()
 
-		if "" == p.config.ExecuteCommand {
-			p.config.ExecuteCommand = defaultExecuteCommand
+			ui = newLogUi(ui, logFileHandle)
 		}
+	}
+
+	ui.Say(fmt.Sprintf("Provisioning with pwsh; script path: %s", scriptPath))
 
-		if (nil != p.config.Inline) && (0 == len(p.config.Inline)) {
-			p.config.Inline = nil
+	if exitCode, e := p.uploadAndExecuteUserScript(context, index, remotePath, scriptPath, ui); nil != e {
+		return e
+	} else {
+		ui.Say(fmt.Sprintf("Provisioning with pwsh; exit code: %d", exitCode))
+
+		if e = p.config.ValidExitCode(exitCode); nil != e {
+			return e
+		} else if p.config.RebootIsEnabled {
+			ui.Say("Checking for pending reboot...")
+
+			if rebootScriptPath, e := p.getInlineScriptFilePath([]string{p.config.RebootPendingCommand}); nil != e {
+				return e
+			} else if exitCode, e = p.uploadAndExecuteScript(context, remotePath, rebootScriptPath, 0, ui); nil != e {
+				return e
+			} else if 1 == exitCode {
+				return p.rebootMachine(context, ui)
+			}
 		}
+	}
 
-		if ("" == p.config.PwshAutoUpdateCommand) && (nil != defaultPwshAutoUpdateTemplate) {
-			var buffer bytes.Buffer
+	return nil
+}
+func (p *Provisioner) getInlineScriptFilePath(lines []string) (string, error) {
+	if (nil == lines) || (0 == len(lines)) {
+		return "", nil
+	} else {
+		if scriptFileHandle, e := tmp.File("pwsh-provisioner"); nil != e {
+			return "", e
+		} else {
+			writer := bufio.NewWriter(scriptFileHandle)
 
-			if err := defaultPwshAutoUpdateTemplate.Execute(&buffer, nil); nil != e {
-				e = packersdk.MultiErrorAppend(e, err)
+			for _, command := range lines {
+				if _, e := writer.WriteString(command + "\n"); nil != e {
+					return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+				}
+			}
+
+			if e = writer.Flush(); nil != e {
+				return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+			} else if e = scriptFileHandle.Close(); nil != e {
+				return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
 			} else {
-				p.config.PwshAutoUpdateCommand = strings.ReplaceAll(strings.ReplaceAll(string(buffer.Bytes()), "\r\n", "\n"), "\r", "\n")
+				return scriptFileHandle.Name(), nil
 			}
 		}
+	}
+}
+func (p *Provisioner) getLocalScriptPath(remotePath string, defaultPath string) string {
+	if "" == remotePath {
+		return defaultPath
+	}
 
-		if "" == p.config.PwshAutoUpdateExecuteCommand {
-			p.config.PwshAutoUpdateExecuteCommand = defaultPwshAutoUpdateExecuteCommand
+	for remoteScriptPath, localScriptPath := range p.remoteScriptPaths {
+		if strings.EqualFold(remoteScriptPath, strings.ReplaceAll(remotePath, `\`, "/")) {
+			return localScriptPath
 		}
+	}
 
-		if "" == p.config.RebootCompleteCommand {
-			p.config.RebootCompleteCommand = defaultRebootCompleteCommand
+	return remotePath
+}
+func (p *Provisioner) getSensitiveValues() []string {
+	sensitiveValues := make([]string, 0)
+
+	if elevatedPassword := p.ElevatedPassword(); "" != elevatedPassword {
+		sensitiveValues = append(sensitiveValues, elevatedPassword)
+	}
+
+	for _, sensitiveValue := range p.config.PackerSensitiveVars {
+		if "" != sensitiveValue {
+			sensitiveValues = append(sensitiveValues, sensitiveValue)
 		}
+	}
 
-		if "" == p.config.RebootInitiateCommand {
-			p.config.RebootInitiateCommand = defaultRebootInitiateCommand
+	for _, sensitiveValue := range p.config.SensitiveEnv {
+		if "" != sensitiveValue {
+			sensitiveValues = append(sensitiveValues, sensitiveValue)
 		}
+	}
 
-		if ("" == p.config.RebootPendingCommand) && (nil != defaultRebootPendingTemplate) {
-			var buffer bytes.Buffer
+	if sensitiveEnv, e := p.getSensitiveEnv(); (nil == e) && ("" != sensitiveEnv) {
+		sensitiveValues = append(sensitiveValues, sensitiveEnv)
+	}
 
-			if err := defaultRebootPendingTemplate.Execute(&buffer, nil); nil != e {
-				e = packersdk.MultiErrorAppend(e, err)
-			} else {
-				p.config.RebootPendingCommand = strings.ReplaceAll(strings.ReplaceAll(string(buffer.Bytes()), "\r\n", "\n"), "\r", "\n")
-			}
+	return sensitiveValues
+}
+func (p *Provisioner) getScriptTimeout(scriptPath string) time.Duration {
+	for _, scriptOverride := range p.config.ScriptOverrides {
+		if (scriptPath == scriptOverride.Path) && (0 < scriptOverride.Timeout) {
+			return scriptOverride.Timeout
 		}
+	}
 
-		if "" == p.config.RebootProgressCommand {
-			p.config.RebootProgressCommand = defaultRebootProgressCommand
+	return p.config.ScriptTimeout
+}
+func (p *Provisioner) getScriptWorkingDirectory(scriptPath string) string {
+	for _, scriptOverride := range p.config.ScriptOverrides {
+		if (scriptPath == scriptOverride.Path) && ("" != scriptOverride.WorkingDirectory) {
+			return scriptOverride.WorkingDirectory
 		}
+	}
+
+	return p.config.WorkingDirectory
+}
+func (p *Provisioner) initializeScriptCollection() ([]string, error) {
+	if inlineScriptFilePath, e := p.getInlineScriptFilePath(p.config.Inline); nil != e {
+		return nil, e
+	} else {
+		scripts := make([]string, len(p.config.Scripts))
 
-		if "" == p.config.RebootValidateCommand {
-			p.config.RebootValidateCommand = defaultRebootValidateCommand
+		if "" != inlineScriptFilePath {
+			scripts = append(scripts, inlineScriptFilePath)
 		}
 
-		if "" == p.config.RemoteEnvVarPath {
-			p.config.RemoteEnvVarPath = fmt.Sprintf(formatRemotePath("ps1", "variables"), uuid.TimeOrderedUUID())
+		copy(scripts, p.config.Scripts)
+
+		return scripts, nil
+	}
+}
+func (p *Provisioner) isScriptPath(path string) bool {
+	for _, scriptPath := range p.config.Scripts {
+		if path == scriptPath {
+			return true
 		}
+	}
+
+	return false
+}
+func (p *Provisioner) killScript(ctx context.Context, ui packersdk.Ui) error {
+	command := p.config.ScriptKillCommand
+
+	// The kill command runs as the same user as the script; the connecting user may not be allowed to signal its processes.
+	if "" != p.config.RunAsUser {
+		command = p.wrapRunAsCommand(command)
+	} else if "" != p.config.ElevatedUser {
+		command = p.wrapElevatedCommand(command)
+	}
+
+	if command, e := interpolate.Render(command, &p.config.ctx); nil != e {
+		return e
+	} else {
+		var elevatedRunner *windowsElevatedRunner
 
-		if "" == p.config.RemotePath {
-			p.config.RemotePath = fmt.Sprintf(formatRemotePath("ps1", "script"), uuid.TimeOrderedUUID())
+		ui.Say(fmt.Sprintf("Terminating PowerShell script; command: %s", command))
+
+		remoteCmd := &packersdk.RemoteCmd{
+			Command: command,
+			Stdin:   p.getStdinReader(),
 		}
 
-		if "" == p.config.RemotePwshAutoUpdatePath {
-			p.config.RemotePwshAutoUpdatePath = fmt.Sprintf(formatRemotePath(defaultPwshAutoUpdateScriptExtension, "installer"), uuid.TimeOrderedUUID())
+		if ("windows" == p.config.OsType) && ("" != p.config.ElevatedUser) {
+			if elevatedRunner, e = p.uploadWindowsElevatedRunner(command, 0, ui); nil != e {
+				return e
+			}
+
+			remoteCmd.Command = elevatedRunner.command()
 		}
 
-		if nil == p.config.Scripts {
-			p.config.Scripts = make([]string, 0)
+		if e = remoteCmd.RunWithUi(ctx, p.communicator, ui); (nil != e) && (nil != elevatedRunner) {
+			if err := p.removeWindowsElevatedRunner(elevatedRunner, ui); nil != err {
+				ui.Error(fmt.Sprintf("Failed to remove elevated scheduled task: %s", err))
+			}
 		}
 
-		if nil == p.config.Vars {
-			p.config.Vars = make([]string, 0)
+		return e
+	}
+}
+func (p *Provisioner) prepareConfig() error {
+	var e error
+
+	if "" == p.config.ErrorActionPreference {
+		p.config.ErrorActionPreference = "Stop"
+	}
+
+	if "" == p.config.ExecutionPolicy {
+		p.config.ExecutionPolicy = "Bypass"
+	}
+
+	if "" == p.config.PowershellEdition {
+		p.config.PowershellEdition = "auto"
+	}
+
+	p.config.PowershellEdition = strings.ToLower(p.config.PowershellEdition)
+
+	if "" == p.config.ProgressPreference {
+		p.config.ProgressPreference = "SilentlyContinue"
+	}
+
+	if "" == p.config.ElevationMethod {
+		p.config.ElevationMethod = "sudo"
+	}
+
+	p.config.ElevationMethod = strings.ToLower(p.config.ElevationMethod)
+
+	defaultElevatedExecuteCommand := getLinuxElevatedExecuteCommandFormat(p.config.ElevationMethod, ("" != p.config.ElevatedPassword))
+	defaultPwshAutoUpdateExecuteCommand := "chmod +x {{.Path}} && {{.Path}}"
+	defaultPwshAutoUpdateScriptExtension := `sh`
+	defaultRebootCompleteCommand := ""
+	defaultRebootInitiateCommand := ""
+	defaultRebootProgressCommand := ""
+	defaultRebootValidateCommand := `pwsh -ExecutionPolicy "Bypass" -NoLogo -NonInteractive -NoProfile -Command "exit 0;"`
+	defaultRemotePathFormat := `%s/packer-pwsh-%s-%%s.%s`
+	defaultRemoteScriptDirectoryPath := `/tmp`
+	defaultScriptKillCommand := `kill_tree() { kill -STOP "$1" 2>/dev/null || true; for child in $(pgrep -P "$1"); do kill_tree "$child"; done; kill -KILL "$1" 2>/dev/null || true; }; for pid in $(pgrep -x pwsh); do if tr '\0' ' ' < "/proc/$pid/cmdline" | grep -qF '{{.Path}}'; then kill_tree "$pid"; fi; done; exit 0`
+
+	var defaultPwshAutoUpdateTemplate *template.Template
+	var defaultRebootPendingTemplate *template.Template
+
+	if "" == p.config.ExecutionTarget {
+		p.config.ExecutionTarget = "remote"
+	}
+
+	p.config.ExecutionTarget = strings.ToLower(p.config.ExecutionTarget)
+
+	if ("" == p.config.OsType) && ("local" == p.config.ExecutionTarget) && ("windows" == runtime.GOOS) {
+		p.config.OsType = "windows"
+	}
+
+	p.config.OsType = strings.ToLower(p.config.OsType)
+
+	if ("" == p.config.PwshPath) && ("windows" != p.config.OsType) {
+		p.config.PwshPath = "pwsh"
+	}
+
+	defaultExecuteCommand := p.getDefaultExecuteCommand("{{.PwshPath}}")
+
+	switch p.config.OsType {
+	case "debian":
+		defaultPwshAutoUpdateTemplate = debianPwshAutoUpdateTemplate
+
+		break
+	case "ubuntu":
+		defaultPwshAutoUpdateTemplate = ubuntuPwshAutoUpdateTemplate
+
+		break
+	case "windows":
+		defaultElevatedExecuteCommand = `%s`
+		defaultPwshAutoUpdateExecuteCommand = p.getDefaultExecuteCommand("powershell")
+		defaultPwshAutoUpdateScriptExtension = `ps1`
+		defaultPwshAutoUpdateTemplate = windowsPwshAutoUpdateTemplate
+		defaultRebootCompleteCommand = `shutdown /a`
+		defaultRebootInitiateCommand = `shutdown /r /f /t 0 /c "packer reboot"`
+		defaultRebootPendingTemplate = windowsRebootPendingTemplate
+		defaultRebootProgressCommand = `shutdown /r /f /t 60 /c "packer reboot test"`
+		defaultRemoteScriptDirectoryPath = `C:/Windows/Temp`
+		defaultScriptKillCommand = `powershell -NoLogo -NonInteractive -NoProfile -Command "Get-CimInstance -ClassName Win32_Process | Where-Object { ($_.ProcessId -ne $PID) -and (@('powershell.exe', 'pwsh.exe') -contains $_.Name) -and ($_.CommandLine -like '*{{.Path}}*') } | ForEach-Object { taskkill /F /T /PID $_.ProcessId }; exit 0;"`
+
+		break
+	default:
+		defaultPwshAutoUpdateTemplate = nil
+		defaultRebootPendingTemplate = nil
+
+		break
+	}
+
+	if "local" == p.config.ExecutionTarget {
+		defaultRemoteScriptDirectoryPath = filepath.ToSlash(os.TempDir())
+	}
+
+	var formatRemotePath = func(extension string, suffix string) string {
+		return fmt.Sprintf(defaultRemotePathFormat, defaultRemoteScriptDirectoryPath, suffix, extension)
+	}
+
+	if "" == p.config.ElevatedExecuteCommand {
+		p.config.ElevatedExecuteCommand = defaultElevatedExecuteCommand
+	}
+
+	if "" == p.config.ExecuteCommand {
+		p.config.ExecuteCommand = defaultExecuteCommand
+	}
+
+	if (nil != p.config.Inline) && (0 == len(p.config.Inline)) {
+		p.config.Inline = nil
+	}
+
+	if p.config.TemplateScripts && (0 == len(p.config.TemplateDelimiters)) {
+		p.config.TemplateDelimiters = []string{"{{", "}}"}
+	}
+
+	if "" == p.config.LintPwshPath {
+		p.config.LintPwshPath = "pwsh"
+	}
+
+	if "" == p.config.OutputPrefix {
+		p.config.OutputPrefix = "packer-output:"
+	}
+
+	if ("" == p.config.PwshAutoUpdateCommand) && (nil != defaultPwshAutoUpdateTemplate) {
+		var buffer bytes.Buffer
+
+		if err := defaultPwshAutoUpdateTemplate.Execute(&buffer, nil); nil != e {
+			e = packersdk.MultiErrorAppend(e, err)
+		} else {
+			p.config.PwshAutoUpdateCommand = strings.ReplaceAll(strings.ReplaceAll(string(buffer.Bytes()), "\r\n", "\n"), "\r", "\n")
 		}
+	}
 
-		if ("" != p.config.ElevatedPassword) && ("" == p.config.ElevatedUser) {
-			e = packersdk.MultiErrorAppend(e, errors.New("Must supply the 'elevated_user' parameter if 'elevated_password' is provided."))
+	if "" == p.config.PwshAutoUpdateExecuteCommand {
+		p.config.PwshAutoUpdateExecuteCommand = defaultPwshAutoUpdateExecuteCommand
+	}
+
+	if "" == p.config.RebootCompleteCommand {
+		p.config.RebootCompleteCommand = defaultRebootCompleteCommand
+	}
+
+	if "" == p.config.RebootInitiateCommand {
+		p.config.RebootInitiateCommand = defaultRebootInitiateCommand
+	}
+
+	if ("" == p.config.RebootPendingCommand) && (nil != defaultRebootPendingTemplate) {
+		var buffer bytes.Buffer
+
+		if err := defaultRebootPendingTemplate.Execute(&buffer, nil); nil != e {
+			e = packersdk.MultiErrorAppend(e, err)
+		} else {
+			p.config.RebootPendingCommand = strings.ReplaceAll(strings.ReplaceAll(string(buffer.Bytes()), "\r\n", "\n"), "\r", "\n")
 		}
+	}
+
+	if "" == p.config.RebootProgressCommand {
+		p.config.RebootProgressCommand = defaultRebootProgressCommand
+	}
+
+	if "" == p.config.RebootValidateCommand {
+		p.config.RebootValidateCommand = defaultRebootValidateCommand
+	}
 
-		if (nil == p.config.Inline) && (0 == len(p.config.Scripts)) {
-			e = packersdk.MultiErrorAppend(e, errors.New("Either a script file or an inline script must be specified."))
-		} else if (nil != p.config.Inline) && (0 < len(p.config.Scripts)) {
-			e = packersdk.MultiErrorAppend(e, errors.New("Only a script file or an inline script can be specified, not both."))
+	if "" == p.config.RemoteEnvVarPath {
+		p.config.RemoteEnvVarPath = fmt.Sprintf(formatRemotePath("ps1", "variables"), uuid.TimeOrderedUUID())
+	}
+
+	if "" == p.config.RemotePath {
+		p.config.RemotePath = fmt.Sprintf(formatRemotePath("ps1", "script"), uuid.TimeOrderedUUID())
+	}
+
+	if "" == p.config.RemotePwshAutoUpdatePath {
+		p.config.RemotePwshAutoUpdatePath = fmt.Sprintf(formatRemotePath(defaultPwshAutoUpdateScriptExtension, "installer"), uuid.TimeOrderedUUID())
+	}
+
+	if "" == p.config.RemoteTranscriptPath {
+		p.config.RemoteTranscriptPath = fmt.Sprintf(formatRemotePath("txt", "transcript"), uuid.TimeOrderedUUID())
+	}
+
+	if ("" == p.config.RunAsExecuteCommand) && ("" != p.config.RunAsUser) {
+		p.config.RunAsExecuteCommand = getLinuxRunAsExecuteCommandFormat(p.config.ElevationMethod, ("" != p.config.ElevatedPassword), p.config.RunAsUser)
+	}
+
+	if "" == p.config.ScriptKillCommand {
+		p.config.ScriptKillCommand = defaultScriptKillCommand
+	}
+
+	if nil == p.config.Scripts {
+		p.config.Scripts = make([]string, 0)
+	}
+
+	if nil == p.config.Vars {
+		p.config.Vars = make([]string, 0)
+	}
+
+	for _, envVar := range p.config.Vars {
+		if fields := strings.SplitN(envVar, "=", 2); (2 != len(fields)) || !envVarNamePattern.MatchString(fields[0]) {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'environment_vars' entry must be in the form NAME=VALUE with a valid environment variable name: %s.", envVar))
 		}
+	}
 
-		if nil != e {
-			return e
+	for name := range p.config.Env {
+		if !envVarNamePattern.MatchString(name) {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'env' name is not a valid environment variable name: %s.", name))
 		}
+	}
 
-		return nil
+	for name := range p.config.SensitiveEnv {
+		if !envVarNamePattern.MatchString(name) {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'sensitive_env' name is not a valid environment variable name: %s.", name))
+		}
 	}
-}
-func (p *Provisioner) Provision(context context.Context, ui packersdk.Ui, communicator packersdk.Communicator, generatedData map[string]interface{}) error {
-	p.communicator = communicator
-	p.config.ctx.Data = generatedData
-	p.generatedData = generatedData
 
-	if p.config.PwshAutoUpdateIsEnabled {
-		if e := p.updatePwshInstallation(context, ui); nil != e {
-			return e
+	if (0 < len(p.config.SensitiveEnv)) && ("" != p.config.ElevatedUser) && ("windows" == p.config.OsType) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'sensitive_env' parameter cannot be combined with 'elevated_user' on Windows."))
+	}
+
+	if ("local" != p.config.ExecutionTarget) && ("remote" != p.config.ExecutionTarget) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'execution_target' parameter must be one of 'local' or 'remote': %s.", p.config.ExecutionTarget))
+	} else if ("local" == p.config.ExecutionTarget) && (("" != p.config.ElevatedUser) || ("" != p.config.RunAsUser) || p.config.PwshAutoUpdateIsEnabled || p.config.RebootIsEnabled) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'local' execution target cannot be combined with 'elevated_user', 'run_as_user', 'pwsh_autoupdate_is_enabled' or 'reboot_is_enabled'."))
+	} else if ("local" == p.config.ExecutionTarget) && (("windows" == p.config.OsType) != ("windows" == runtime.GOOS)) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'os_type' parameter must match the host operating system when 'execution_target' is 'local': %s.", runtime.GOOS))
+	}
+
+	if "" != p.config.RunAsUser {
+		if "windows" == p.config.OsType {
+			e = packersdk.MultiErrorAppend(e, errors.New("The 'run_as_user' parameter is not supported when 'os_type' is 'windows'."))
+		} else if !userNamePattern.MatchString(p.config.RunAsUser) {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'run_as_user' parameter is not a valid user name: %s.", p.config.RunAsUser))
 		}
 	}
 
-	if scriptPaths, e := p.initializeScriptCollection(); nil != e {
-		return e
-	} else {
-		return p.executeScriptCollection(context, scriptPaths, ui)
+	if !isElevationMethod(p.config.ElevationMethod) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'elevation_method' parameter must be one of 'doas', 'su' or 'sudo': %s.", p.config.ElevationMethod))
+	} else if ("doas" == p.config.ElevationMethod) && ("" != p.config.ElevatedPassword) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'doas' elevation method does not support 'elevated_password'; configure doas to permit the user without a password."))
+	} else if ("su" == p.config.ElevationMethod) && ("" != p.config.ElevatedPassword) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'su' elevation method does not support 'elevated_password'; su reads the password from a terminal, so use 'sudo' instead."))
 	}
-}
 
-func (p *Provisioner) executeScriptCollection(context context.Context, scriptPaths []string, ui packersdk.Ui) error {
-	remotePath := p.config.RemotePath
-	p.generatedData["Path"] = remotePath
+	if !isActionPreference(p.config.ErrorActionPreference) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'error_action_preference' parameter is not a valid PowerShell action preference: %s.", p.config.ErrorActionPreference))
+	}
 
-	for _, scriptPath := range scriptPaths {
-		ui.Say(fmt.Sprintf("Provisioning with pwsh; script path: %s", scriptPath))
+	if ("auto" != p.config.PowershellEdition) && ("core" != p.config.PowershellEdition) && ("desktop" != p.config.PowershellEdition) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'powershell_edition' parameter must be one of 'auto', 'core' or 'desktop': %s.", p.config.PowershellEdition))
+	} else if ("desktop" == p.config.PowershellEdition) && ("windows" != p.config.OsType) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'desktop' PowerShell edition is only available when 'os_type' is 'windows'."))
+	} else if ("auto" != p.config.PowershellEdition) && !strings.Contains(p.config.ExecuteCommand, ".PwshPath") {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The '%s' PowerShell edition requires 'execute_command' to reference {{.PwshPath}}; the edition of any other interpreter cannot be verified.", p.config.PowershellEdition))
+	}
 
-		if exitCode, e := p.uploadAndExecuteScript(context, remotePath, scriptPath, ui); nil != e {
-			return e
+	if !isActionPreference(p.config.ProgressPreference) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'progress_preference' parameter is not a valid PowerShell action preference: %s.", p.config.ProgressPreference))
+	}
+
+	if p.config.UseFileParameter && (0 < len(p.config.SensitiveEnv)) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'sensitive_env' parameter cannot be combined with 'use_file_parameter'."))
+	}
+
+	if p.config.UseFileParameter && p.hasEnvVars() {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'environment_vars' and 'env' parameters cannot be combined with 'use_file_parameter'."))
+	}
+
+	if p.config.UseFileParameter && p.hasWorkingDirectory() {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'working_directory' parameter cannot be combined with 'use_file_parameter'."))
+	}
+
+	if !isExecutionPolicy(p.config.ExecutionPolicy) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'execution_policy' parameter is not a valid PowerShell execution policy: %s.", p.config.ExecutionPolicy))
+	} else if strings.EqualFold("AllSigned", p.config.ExecutionPolicy) && ((nil != p.config.Inline) || (0 < len(p.config.Downloads)) || p.hasEnvVars() || p.config.RebootIsEnabled || p.config.TemplateScripts || ("" != p.config.TranscriptDir)) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'AllSigned' execution policy cannot be combined with generated scripts ('inline', 'download', 'environment_vars', 'env', 'reboot_is_enabled', 'template_scripts' or 'transcript_dir')."))
+	}
+
+	if (0 < len(p.config.TemplateDelimiters)) && !p.config.TemplateScripts {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'template_delimiters' parameter requires 'template_scripts' to be enabled."))
+	} else if (0 < len(p.config.TemplateDelimiters)) && ((2 != len(p.config.TemplateDelimiters)) || ("" == p.config.TemplateDelimiters[0]) || ("" == p.config.TemplateDelimiters[1])) {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'template_delimiters' parameter must contain exactly two non-empty delimiters."))
+	}
+
+	if "" != p.config.ScriptManifestPath {
+		if p.config.TemplateScripts {
+			e = packersdk.MultiErrorAppend(e, errors.New("The 'script_sha256_manifest' parameter cannot be combined with 'template_scripts'; rendered scripts never match their checksums."))
+		} else if scriptChecksums, err := verifySha256Manifest(p.config.ScriptManifestPath, p.config.Scripts); nil != err {
+			// The manifest is verified before Provision registers the secrets, so the script paths in the error are redacted here.
+			packersdk.LogSecretFilter.Set(p.getSensitiveValues()...)
+
+			e = packersdk.MultiErrorAppend(e, errors.New(packersdk.LogSecretFilter.FilterString(err.Error())))
 		} else {
-			ui.Say(fmt.Sprintf("Provisioning with pwsh; exit code: %d", exitCode))
+			p.scriptChecksums = scriptChecksums
+		}
+	}
 
-			if p.config.ValidExitCode(exitCode); nil != e {
-				return e
-			} else {
-				if p.config.RebootIsEnabled {
-					ui.Say("Checking for pending reboot...")
+	if ("" != p.config.ElevatedPassword) && ("" == p.config.ElevatedUser) {
+		e = packersdk.MultiErrorAppend(e, errors.New("Must supply the 'elevated_user' parameter if 'elevated_password' is provided."))
+	}
 
-					if rebootScriptPath, e := p.getInlineScriptFilePath([]string{p.config.RebootPendingCommand}); nil != e {
-						return e
-					} else {
-						if exitCode, e = p.uploadAndExecuteScript(context, remotePath, rebootScriptPath, ui); nil != e {
-							return e
-						} else if 1 == exitCode {
-							if e = p.rebootMachine(context, ui); nil != e {
-								return e
-							}
-						}
-					}
-				}
-			}
+	for _, download := range p.config.Downloads {
+		if ("" == download.Destination) || ("" == download.Source) {
+			e = packersdk.MultiErrorAppend(e, errors.New("Must supply both the 'source' and 'destination' parameters for each 'download' block."))
 		}
 	}
 
-	return nil
-}
-func (p *Provisioner) getInlineScriptFilePath(lines []string) (string, error) {
-	if (nil == lines) || (0 == len(lines)) {
-		return "", nil
-	} else {
-		if scriptFileHandle, e := tmp.File("pwsh-provisioner"); nil != e {
-			return "", e
-		} else {
-			writer := bufio.NewWriter(scriptFileHandle)
+	if 0 > p.config.ScriptTimeout {
+		e = packersdk.MultiErrorAppend(e, errors.New("The 'script_timeout' parameter must not be negative."))
+	}
 
-			for _, command := range lines {
-				if _, e := writer.WriteString(command + "\n"); nil != e {
-					return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
-				}
-			}
+	if strings.ContainsAny(p.config.WorkingDirectory, workingDirectoryInvalidCharacters) {
+		e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'working_directory' parameter must not contain any of %s.", workingDirectoryInvalidCharacters))
+	}
 
-			if e = writer.Flush(); nil != e {
-				return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
-			} else if e = scriptFileHandle.Close(); nil != e {
-				return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	for _, scriptOverride := range p.config.ScriptOverrides {
+		if !p.isScriptPath(scriptOverride.Path) {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'script_override' path must match an entry in 'scripts': %s.", scriptOverride.Path))
+		}
+
+		if 0 > scriptOverride.Timeout {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'script_override' timeout must not be negative: %s.", scriptOverride.Path))
+		}
+
+		if strings.ContainsAny(scriptOverride.WorkingDirectory, workingDirectoryInvalidCharacters) {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf("The 'script_override' working directory must not contain any of %s: %s.", workingDirectoryInvalidCharacters, scriptOverride.Path))
+		}
+	}
+
+	// Inline lines are excluded from decoding so that 'template_scripts' can defer rendering until the build data is known.
+	if !p.config.TemplateScripts {
+		for index, line := range p.config.Inline {
+			if rendered, err := interpolate.Render(line, &p.config.ctx); nil != err {
+				e = packersdk.MultiErrorAppend(e, fmt.Errorf("Error rendering the 'inline' parameter: %s.", err))
 			} else {
-				return scriptFileHandle.Name(), nil
+				p.config.Inline[index] = rendered
+			}
+		}
+	} else if 2 == len(p.config.TemplateDelimiters) {
+		for _, line := range p.config.Inline {
+			if _, err := p.parseTemplate(line); nil != err {
+				e = packersdk.MultiErrorAppend(e, fmt.Errorf("Error parsing the 'inline' parameter: %s.", err))
 			}
 		}
 	}
+
+	if (nil == e) && p.config.Lint {
+		e = p.lintScripts()
+	}
+
+	if nil != e {
+		return e
+	}
+
+	return nil
 }
-func (p *Provisioner) initializeScriptCollection() ([]string, error) {
-	if inlineScriptFilePath, e := p.getInlineScriptFilePath(p.config.Inline); nil != e {
-		return nil, e
-	} else {
-		scripts := make([]string, len(p.config.Scripts))
+func (p *Provisioner) provision(context context.Context, ui packersdk.Ui) error {
+	if p.config.PwshAutoUpdateIsEnabled {
+		if e := p.updatePwshInstallation(context, ui); nil != e {
+			return e
+		}
+	}
 
-		if "" != inlineScriptFilePath {
-			scripts = append(scripts, inlineScriptFilePath)
+	// A custom execute command that does not reference the resolved path names its own interpreter, which may not even be PowerShell.
+	if strings.Contains(p.config.ExecuteCommand, ".PwshPath") {
+		if e := p.resolvePwshInstallation(context, ui); nil != e {
+			return e
 		}
+	} else {
+		ui.Say("Skipping PowerShell installation resolution; the execute command does not reference {{.PwshPath}}")
+	}
 
-		copy(scripts, p.config.Scripts)
+	return p.provisionScripts(context, ui)
+}
+func (p *Provisioner) provisionScripts(context context.Context, ui packersdk.Ui) error {
+	if scriptPaths, e := p.initializeScriptCollection(); nil != e {
+		return e
+	} else {
+		if e = p.executeScriptCollection(context, scriptPaths, ui); nil != e {
+			return e
+		} else if e = p.downloadArtifacts(context, ui); nil != e {
+			return e
+		}
 
-		return scripts, nil
+		return p.writeOutputFile(ui)
 	}
 }
 func (p *Provisioner) rebootMachine(ctx context.Context, ui packersdk.Ui) error {
@@ -360,7 +828,7 @@ func (p *Provisioner) rebootMachine(ctx context.Context, ui packersdk.Ui) error
 			ui.Say(fmt.Sprintf("Waiting for machine reboot; command: %s", p.config.RebootProgressCommand))
 
 			for {
-				time.Sleep(13 * time.Second)
+				time.Sleep(rebootPollInterval)
 
 				remoteCmd = &packersdk.RemoteCmd{Command: p.config.RebootProgressCommand}
 
@@ -395,7 +863,7 @@ func (p *Provisioner) rebootMachine(ctx context.Context, ui packersdk.Ui) error
 					}
 				}
 
-				time.Sleep(13 * time.Second)
+				time.Sleep(rebootPollInterval)
 			}
 
 			ui.Say(fmt.Sprintf("Completed machine reboot; exit code: %d", exitCode))
@@ -404,6 +872,97 @@ func (p *Provisioner) rebootMachine(ctx context.Context, ui packersdk.Ui) error
 		}
 	}
 }
+func (p *Provisioner) removeRemoteFiles(ui packersdk.Ui, paths ...string) error {
+	var command string
+
+	quotedPaths := make([]string, len(paths))
+
+	if "windows" == p.config.OsType {
+		for index, path := range paths {
+			quotedPaths[index] = "'" + strings.ReplaceAll(path, "'", "''") + "'"
+		}
+
+		command = fmt.Sprintf("powershell -NoLogo -NonInteractive -NoProfile -Command \"Remove-Item -ErrorAction SilentlyContinue -Force -Path %s;\"", strings.Join(quotedPaths, ", "))
+	} else {
+		for index, path := range paths {
+			quotedPaths[index] = quoteLinuxArgument(path)
+		}
+
+		command = fmt.Sprintf("rm -f %s", strings.Join(quotedPaths, " "))
+	}
+
+	return p.runCleanupCommand(command, ui)
+}
+func (p *Provisioner) reprovision(context context.Context, ui packersdk.Ui) error {
+	// The installation updated and resolved by Provision is still in place; only the scripts are run again.
+	if e := p.provisionScripts(context, newRedactUi(ui)); nil != e {
+		return errors.New(packersdk.LogSecretFilter.FilterString(e.Error()))
+	}
+
+	return nil
+}
+func (p *Provisioner) resolvePwshInstallation(ctx context.Context, ui packersdk.Ui) error {
+	var buffer bytes.Buffer
+	var command string
+
+	templateData := map[string]string{
+		"Edition": p.config.PowershellEdition,
+		"Prefix":  pwshResolvePrefix,
+	}
+
+	if "windows" == p.config.OsType {
+		templateData["PwshPath"] = strings.ReplaceAll(p.config.PwshPath, "'", "''")
+
+		if e := windowsPwshResolveTemplate.Execute(&buffer, templateData); nil != e {
+			return fmt.Errorf(pwshResolvingErrorFormat, e)
+		}
+
+		encodedCommand := make([]byte, 0)
+
+		for _, codeUnit := range utf16.Encode([]rune(buffer.String())) {
+			encodedCommand = append(encodedCommand, byte(codeUnit), byte(codeUnit>>8))
+		}
+
+		command = fmt.Sprintf("powershell -NoLogo -NonInteractive -NoProfile -EncodedCommand %s", base64.StdEncoding.EncodeToString(encodedCommand))
+	} else {
+		templateData["PwshPath"] = strings.ReplaceAll(p.config.PwshPath, "'", `'\''`)
+
+		if e := linuxPwshResolveTemplate.Execute(&buffer, templateData); nil != e {
+			return fmt.Errorf(pwshResolvingErrorFormat, e)
+		}
+
+		command = fmt.Sprintf("echo %s | base64 -d | sh", base64.StdEncoding.EncodeToString(buffer.Bytes()))
+	}
+
+	ui.Say(fmt.Sprintf("Resolving PowerShell installation; edition: %s", p.config.PowershellEdition))
+
+	captureUi := newCaptureUi(ui, pwshResolvePrefix)
+	remoteCmd := &packersdk.RemoteCmd{Command: command}
+
+	if e := remoteCmd.RunWithUi(ctx, p.communicator, captureUi); nil != e {
+		return fmt.Errorf(pwshResolvingErrorFormat, e)
+	} else if lines := captureUi.Lines(); (0 != remoteCmd.ExitStatus()) || (0 == len(lines)) {
+		return fmt.Errorf(pwshResolvingErrorFormat, fmt.Sprintf("no '%s' edition installation was found", p.config.PowershellEdition))
+	} else if fields := strings.SplitN(lines[len(lines)-1], "|", 3); 3 != len(fields) {
+		return fmt.Errorf(pwshResolvingErrorFormat, fmt.Sprintf("unexpected output: %s", lines[len(lines)-1]))
+	} else if ("auto" != p.config.PowershellEdition) && !strings.EqualFold(p.config.PowershellEdition, fields[1]) {
+		return fmt.Errorf(pwshResolvingErrorFormat, fmt.Sprintf("requested the '%s' edition but found '%s' at %s", p.config.PowershellEdition, fields[1], fields[0]))
+	} else {
+		p.generatedData["PwshEdition"] = fields[1]
+		p.generatedData["PwshPath"] = fields[0]
+		p.generatedData["PwshVersion"] = fields[2]
+
+		ui.Say(fmt.Sprintf("Resolved PowerShell installation; path: %s; edition: %s; version: %s", fields[0], fields[1], fields[2]))
+
+		return nil
+	}
+}
+func (p *Provisioner) runCleanupCommand(command string, ui packersdk.Ui) error {
+	// The build context may already be cancelled at this point; the cleanup must run regardless.
+	remoteCmd := &packersdk.RemoteCmd{Command: command}
+
+	return remoteCmd.RunWithUi(context.Background(), p.communicator, ui)
+}
 func (p *Provisioner) updatePwshInstallation(context context.Context, ui packersdk.Ui) error {
 	remotePath := p.config.RemotePwshAutoUpdatePath
 	p.generatedData["Path"] = remotePath
@@ -413,78 +972,175 @@ func (p *Provisioner) updatePwshInstallation(context context.Context, ui packers
 	} else {
 		originalExecuteCommand := p.config.ExecuteCommand
 		p.config.ExecuteCommand = p.config.PwshAutoUpdateExecuteCommand
-		_, e = p.uploadAndExecuteScript(context, remotePath, updateScriptPath, ui)
+		exitCode, e := p.uploadAndExecuteScript(context, remotePath, updateScriptPath, 0, ui)
 		p.config.ExecuteCommand = originalExecuteCommand
 
+		if (nil == e) && (0 != exitCode) {
+			return fmt.Errorf(pwshUpdatingErrorFormat, fmt.Sprintf("exit code: %d", exitCode))
+		}
+
 		return e
 	}
 }
-func (p *Provisioner) uploadAndExecuteScript(ctx context.Context, remotePath string, scriptPath string, ui packersdk.Ui) (int, error) {
+func (p *Provisioner) uploadAndExecuteNamedScript(ctx context.Context, remotePath string, scriptPath string, scriptName string, timeout time.Duration, ui packersdk.Ui) (int, error) {
+	// The uploaded file may be a generated copy (rendered template, transcript wrapper); errors name the script it was made from.
 	exitCode := -1
 
 	var command string
+	var errorRecord *pwshErrorRecord
 
-	if "" != p.config.ElevatedUser {
+	if "" != p.config.RunAsUser {
+		command = p.wrapRunAsCommand(p.config.ExecuteCommand)
+	} else if "" != p.config.ElevatedUser {
 		command = p.ElevatedExecuteCommand()
 	} else {
 		command = p.config.ExecuteCommand
 	}
 
-	if command, e := interpolate.Render(command, &p.config.ctx); nil != e {
+	if command, e := p.renderScriptCommand(command); nil != e {
 		return exitCode, e
 	} else {
-		if scriptFileInfo, e := os.Stat(scriptPath); nil != e {
-			return exitCode, fmt.Errorf(pwshScriptStatingErrorFormat, e)
+		if scriptContent, scriptFileInfo, e := p.readScript(scriptPath); nil != e {
+			return exitCode, e
 		} else {
 			if os.IsPathSeparator(remotePath[len(remotePath)-1]) {
 				remotePath += filepath.Base(scriptFileInfo.Name())
 			}
 
-			if scriptFileHandle, e := os.Open(scriptPath); nil != e {
-				return exitCode, fmt.Errorf(pwshScriptOpeningErrorFormat, e)
-			} else {
-				if e = (retry.Config{
-					StartTimeout: defaultStartTimeout,
-					Tries:        defaultTries,
-				}.Run(
-					ctx,
-					func(ctx context.Context) error {
-						if _, e := scriptFileHandle.Seek(0, 0); nil != e {
+			p.remoteScriptPaths[remotePath] = scriptName
+
+			if e = (retry.Config{
+				StartTimeout: defaultStartTimeout,
+				Tries:        defaultTries,
+			}.Run(
+				ctx,
+				func(ctx context.Context) error {
+					if e := p.communicator.Upload(remotePath, bytes.NewReader(scriptContent), &scriptFileInfo); nil != e {
+						return fmt.Errorf(pwshScriptUploadingErrorFormat, e)
+					} else {
+						var elevatedRunner *windowsElevatedRunner
+
+						errorCollector := newLineCollector(pwshErrorRecordPrefix)
+						executeCtx := ctx
+						outputCollector := newLineCollector(p.config.OutputPrefix)
+						remoteCmd := &packersdk.RemoteCmd{
+							Command: command,
+							Stderr:  errorCollector,
+							Stdout:  outputCollector,
+						}
+
+						if remoteCmd.Stdin, e = p.getScriptStdinReader(); nil != e {
 							return e
-						} else if e = p.communicator.Upload(remotePath, scriptFileHandle, &scriptFileInfo); nil != e {
-							return fmt.Errorf(pwshScriptUploadingErrorFormat, e)
-						} else {
-							if ("windows" == p.config.OsType) && ("" != p.config.ElevatedUser) {
-								if command, e = guestexec.GenerateElevatedRunner(command, p); nil != e {
-									return e
+						}
+
+						if ("windows" == p.config.OsType) && ("" != p.config.ElevatedUser) {
+							if elevatedRunner, e = p.uploadWindowsElevatedRunner(command, timeout, ui); nil != e {
+								return e
+							}
+
+							remoteCmd.Command = elevatedRunner.command()
+						}
+
+						if 0 < timeout {
+							var cancel context.CancelFunc
+
+							executeCtx, cancel = context.WithTimeout(ctx, timeout)
+
+							defer cancel()
+						}
+
+						if e = remoteCmd.RunWithUi(executeCtx, p.communicator, ui); nil != e {
+							if nil != elevatedRunner {
+								if err := p.removeWindowsElevatedRunner(elevatedRunner, ui); nil != err {
+									ui.Error(fmt.Sprintf("Failed to remove elevated scheduled task: %s", err))
 								}
 							}
 
-							remoteCmd := &packersdk.RemoteCmd{Command: command}
+							if (nil == ctx.Err()) && errors.Is(executeCtx.Err(), context.DeadlineExceeded) {
+								if err := p.killScript(ctx, ui); nil != err {
+									ui.Error(fmt.Sprintf("Failed to terminate PowerShell script: %s", err))
+								}
 
-							if e = remoteCmd.RunWithUi(ctx, p.communicator, ui); nil != e {
-								return e
-							} else {
-								exitCode = remoteCmd.ExitStatus()
+								return fmt.Errorf(pwshScriptTimeoutErrorFormat, scriptName, timeout)
+							}
+
+							return e
+						} else {
+							exitCode = remoteCmd.ExitStatus()
+
+							errorRecord = parseErrorRecord(errorCollector.Flush())
 
-								return nil
+							if outputs, e := parseOutputs(outputCollector.Flush()); nil != e {
+								return fmt.Errorf(pwshScriptExecutingErrorFormat, e)
+							} else {
+								for name, value := range outputs {
+									p.generatedData[name] = value
+								}
 							}
+
+							return nil
 						}
-					},
-				)); nil != e {
-					return exitCode, e
-				} else {
-					if e = scriptFileHandle.Close(); nil != e {
-						return exitCode, fmt.Errorf(pwshScriptClosingErrorFormat, e)
 					}
+				},
+			)); nil != e {
+				if !p.isScriptPath(scriptPath) {
+					os.Remove(scriptPath)
+				}
 
-					if e = os.Remove(scriptFileHandle.Name()); nil != e {
+				return exitCode, e
+			} else {
+				if !p.isScriptPath(scriptPath) {
+					if e = os.Remove(scriptPath); nil != e {
 						return exitCode, fmt.Errorf(pwshScriptRemovingErrorFormat, e)
 					}
+				}
+
+				if (0 != exitCode) && (nil != errorRecord) {
+					errorRecord.ScriptName = p.getLocalScriptPath(errorRecord.ScriptName, scriptName)
+
+					ui.Error(errorRecord.Position)
+					ui.Error(errorRecord.ScriptStackTrace)
 
-					return exitCode, nil
+					return exitCode, fmt.Errorf(pwshScriptExecutingErrorFormat, errorRecord)
 				}
+
+				return exitCode, nil
+			}
+		}
+	}
+}
+func (p *Provisioner) uploadAndExecuteScript(ctx context.Context, remotePath string, scriptPath string, timeout time.Duration, ui packersdk.Ui) (int, error) {
+	return p.uploadAndExecuteNamedScript(ctx, remotePath, scriptPath, scriptPath, timeout, ui)
+}
+func (p *Provisioner) uploadAndExecuteUserScript(ctx context.Context, index int, remotePath string, scriptPath string, ui packersdk.Ui) (int, error) {
+	timeout := p.getScriptTimeout(scriptPath)
+
+	p.generatedData["WorkingDirectory"] = p.getScriptWorkingDirectory(scriptPath)
+
+	defer func() {
+		p.generatedData["WorkingDirectory"] = p.config.WorkingDirectory
+	}()
+
+	uploadScriptPath := scriptPath
+
+	if p.config.TemplateScripts {
+		if renderedScriptPath, e := p.renderScriptTemplate(scriptPath); nil != e {
+			return -1, e
+		} else {
+			defer os.RemoveAll(filepath.Dir(renderedScriptPath))
+
+			// The rendered copy is uploaded in place of the generated inline script, which must still be removed.
+			if !p.isScriptPath(scriptPath) {
+				defer os.Remove(scriptPath)
 			}
+
+			uploadScriptPath = renderedScriptPath
 		}
 	}
+
+	if "" != p.config.TranscriptDir {
+		return p.uploadAndExecuteTranscribedScript(ctx, index, remotePath, scriptPath, uploadScriptPath, timeout, ui)
+	}
+
+	return p.uploadAndExecuteNamedScript(ctx, remotePath, uploadScriptPath, scriptPath, timeout, ui)
 }
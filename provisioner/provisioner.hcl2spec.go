@@ -10,40 +10,69 @@ import (
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	PackerBuildName              *string           `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
-	PackerBuilderType            *string           `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
-	PackerCoreVersion            *string           `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
-	PackerDebug                  *bool             `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
-	PackerForce                  *bool             `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
-	PackerOnError                *string           `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
-	PackerUserVars               map[string]string `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
-	PackerSensitiveVars          []string          `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
-	Inline                       []string          `cty:"inline" hcl:"inline"`
-	Script                       *string           `cty:"script" hcl:"script"`
-	Scripts                      []string          `cty:"scripts" hcl:"scripts"`
-	ValidExitCodes               []int             `mapstructure:"valid_exit_codes" cty:"valid_exit_codes" hcl:"valid_exit_codes"`
-	Vars                         []string          `mapstructure:"environment_vars" cty:"environment_vars" hcl:"environment_vars"`
-	Env                          map[string]string `mapstructure:"env" cty:"env" hcl:"env"`
-	EnvVarFormat                 *string           `mapstructure:"env_var_format" cty:"env_var_format" hcl:"env_var_format"`
-	Binary                       *bool             `cty:"binary" hcl:"binary"`
-	RemotePath                   *string           `mapstructure:"remote_path" cty:"remote_path" hcl:"remote_path"`
-	ExecuteCommand               *string           `mapstructure:"execute_command" cty:"execute_command" hcl:"execute_command"`
-	ElevatedEnvVarFormat         *string           `mapstructure:"elevated_env_var_format" cty:"elevated_env_var_format" hcl:"elevated_env_var_format"`
-	ElevatedExecuteCommand       *string           `mapstructure:"elevated_execute_command" cty:"elevated_execute_command" hcl:"elevated_execute_command"`
-	ElevatedPassword             *string           `mapstructure:"elevated_password" cty:"elevated_password" hcl:"elevated_password"`
-	ElevatedUser                 *string           `mapstructure:"elevated_user" cty:"elevated_user" hcl:"elevated_user"`
-	OsType                       *string           `mapstructure:"os_type" cty:"os_type" hcl:"os_type"`
-	PwshAutoUpdateCommand        *string           `mapstructure:"pwsh_autoupdate_command" cty:"pwsh_autoupdate_command" hcl:"pwsh_autoupdate_command"`
-	PwshAutoUpdateExecuteCommand *string           `mapstructure:"pwsh_autoupdate_execute_command" cty:"pwsh_autoupdate_execute_command" hcl:"pwsh_autoupdate_execute_command"`
-	PwshAutoUpdateIsEnabled      *bool             `mapstructure:"pwsh_autoupdate_is_enabled" cty:"pwsh_autoupdate_is_enabled" hcl:"pwsh_autoupdate_is_enabled"`
-	RebootCompleteCommand        *string           `mapstructure:"reboot_complete_command" cty:"reboot_complete_command" hcl:"reboot_complete_command"`
-	RebootInitiateCommand        *string           `mapstructure:"reboot_initiate_command" cty:"reboot_initiate_command" hcl:"reboot_initiate_command"`
-	RebootIsEnabled              *bool             `mapstructure:"reboot_is_enabled" cty:"reboot_is_enabled" hcl:"reboot_is_enabled"`
-	RebootPendingCommand         *string           `mapstructure:"reboot_pending_command" cty:"reboot_pending_command" hcl:"reboot_pending_command"`
-	RebootProgressCommand        *string           `mapstructure:"reboot_progress_command" cty:"reboot_progress_command" hcl:"reboot_progress_command"`
-	RebootValidateCommand        *string           `mapstructure:"reboot_validate_command" cty:"reboot_validate_command" hcl:"reboot_validate_command"`
-	RemoteEnvVarPath             *string           `mapstructure:"remote_env_var_path" cty:"remote_env_var_path" hcl:"remote_env_var_path"`
-	RemotePwshAutoUpdatePath     *string           `mapstructure:"remote_pwsh_autoupdate_path" cty:"remote_pwsh_autoupdate_path" hcl:"remote_pwsh_autoupdate_path"`
+	PackerBuildName              *string              `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType            *string              `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion            *string              `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                  *bool                `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                  *bool                `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError                *string              `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars               map[string]string    `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars          []string             `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	Inline                       []string             `cty:"inline" hcl:"inline"`
+	Script                       *string              `cty:"script" hcl:"script"`
+	Scripts                      []string             `cty:"scripts" hcl:"scripts"`
+	ValidExitCodes               []int                `mapstructure:"valid_exit_codes" cty:"valid_exit_codes" hcl:"valid_exit_codes"`
+	Vars                         []string             `mapstructure:"environment_vars" cty:"environment_vars" hcl:"environment_vars"`
+	Env                          map[string]string    `mapstructure:"env" cty:"env" hcl:"env"`
+	EnvVarFormat                 *string              `mapstructure:"env_var_format" cty:"env_var_format" hcl:"env_var_format"`
+	Binary                       *bool                `cty:"binary" hcl:"binary"`
+	RemotePath                   *string              `mapstructure:"remote_path" cty:"remote_path" hcl:"remote_path"`
+	ExecuteCommand               *string              `mapstructure:"execute_command" cty:"execute_command" hcl:"execute_command"`
+	Downloads                    []FlatDownload       `mapstructure:"download" cty:"download" hcl:"download"`
+	ElevatedEnvVarFormat         *string              `mapstructure:"elevated_env_var_format" cty:"elevated_env_var_format" hcl:"elevated_env_var_format"`
+	ElevatedExecuteCommand       *string              `mapstructure:"elevated_execute_command" cty:"elevated_execute_command" hcl:"elevated_execute_command"`
+	ElevatedPassword             *string              `mapstructure:"elevated_password" cty:"elevated_password" hcl:"elevated_password"`
+	ElevatedUser                 *string              `mapstructure:"elevated_user" cty:"elevated_user" hcl:"elevated_user"`
+	ElevationMethod              *string              `mapstructure:"elevation_method" cty:"elevation_method" hcl:"elevation_method"`
+	ErrorActionPreference        *string              `mapstructure:"error_action_preference" cty:"error_action_preference" hcl:"error_action_preference"`
+	ExecutionPolicy              *string              `mapstructure:"execution_policy" cty:"execution_policy" hcl:"execution_policy"`
+	ExecutionTarget              *string              `mapstructure:"execution_target" cty:"execution_target" hcl:"execution_target"`
+	ExtraArguments               []string             `mapstructure:"extra_arguments" cty:"extra_arguments" hcl:"extra_arguments"`
+	Lint                         *bool                `mapstructure:"lint" cty:"lint" hcl:"lint"`
+	LintAnalyzerIsEnabled        *bool                `mapstructure:"lint_analyzer_is_enabled" cty:"lint_analyzer_is_enabled" hcl:"lint_analyzer_is_enabled"`
+	LintPwshPath                 *string              `mapstructure:"lint_pwsh_path" cty:"lint_pwsh_path" hcl:"lint_pwsh_path"`
+	LogDir                       *string              `mapstructure:"log_dir" cty:"log_dir" hcl:"log_dir"`
+	NoProfile                    *bool                `mapstructure:"no_profile" cty:"no_profile" hcl:"no_profile"`
+	OsType                       *string              `mapstructure:"os_type" cty:"os_type" hcl:"os_type"`
+	OutputFile                   *string              `mapstructure:"output_file" cty:"output_file" hcl:"output_file"`
+	OutputPrefix                 *string              `mapstructure:"output_prefix" cty:"output_prefix" hcl:"output_prefix"`
+	PowershellEdition            *string              `mapstructure:"powershell_edition" cty:"powershell_edition" hcl:"powershell_edition"`
+	ProgressPreference           *string              `mapstructure:"progress_preference" cty:"progress_preference" hcl:"progress_preference"`
+	PwshAutoUpdateCommand        *string              `mapstructure:"pwsh_autoupdate_command" cty:"pwsh_autoupdate_command" hcl:"pwsh_autoupdate_command"`
+	PwshAutoUpdateExecuteCommand *string              `mapstructure:"pwsh_autoupdate_execute_command" cty:"pwsh_autoupdate_execute_command" hcl:"pwsh_autoupdate_execute_command"`
+	PwshAutoUpdateIsEnabled      *bool                `mapstructure:"pwsh_autoupdate_is_enabled" cty:"pwsh_autoupdate_is_enabled" hcl:"pwsh_autoupdate_is_enabled"`
+	PwshPath                     *string              `mapstructure:"pwsh_path" cty:"pwsh_path" hcl:"pwsh_path"`
+	RebootCompleteCommand        *string              `mapstructure:"reboot_complete_command" cty:"reboot_complete_command" hcl:"reboot_complete_command"`
+	RebootInitiateCommand        *string              `mapstructure:"reboot_initiate_command" cty:"reboot_initiate_command" hcl:"reboot_initiate_command"`
+	RebootIsEnabled              *bool                `mapstructure:"reboot_is_enabled" cty:"reboot_is_enabled" hcl:"reboot_is_enabled"`
+	RebootPendingCommand         *string              `mapstructure:"reboot_pending_command" cty:"reboot_pending_command" hcl:"reboot_pending_command"`
+	RebootProgressCommand        *string              `mapstructure:"reboot_progress_command" cty:"reboot_progress_command" hcl:"reboot_progress_command"`
+	RebootValidateCommand        *string              `mapstructure:"reboot_validate_command" cty:"reboot_validate_command" hcl:"reboot_validate_command"`
+	RemoteEnvVarPath             *string              `mapstructure:"remote_env_var_path" cty:"remote_env_var_path" hcl:"remote_env_var_path"`
+	RemotePwshAutoUpdatePath     *string              `mapstructure:"remote_pwsh_autoupdate_path" cty:"remote_pwsh_autoupdate_path" hcl:"remote_pwsh_autoupdate_path"`
+	RemoteTranscriptPath         *string              `mapstructure:"remote_transcript_path" cty:"remote_transcript_path" hcl:"remote_transcript_path"`
+	RunAsExecuteCommand          *string              `mapstructure:"run_as_execute_command" cty:"run_as_execute_command" hcl:"run_as_execute_command"`
+	RunAsUser                    *string              `mapstructure:"run_as_user" cty:"run_as_user" hcl:"run_as_user"`
+	SensitiveEnv                 map[string]string    `mapstructure:"sensitive_env" cty:"sensitive_env" hcl:"sensitive_env"`
+	ScriptKillCommand            *string              `mapstructure:"script_kill_command" cty:"script_kill_command" hcl:"script_kill_command"`
+	ScriptManifestPath           *string              `mapstructure:"script_sha256_manifest" cty:"script_sha256_manifest" hcl:"script_sha256_manifest"`
+	ScriptOverrides              []FlatScriptOverride `mapstructure:"script_override" cty:"script_override" hcl:"script_override"`
+	ScriptTimeout                *string              `mapstructure:"script_timeout" cty:"script_timeout" hcl:"script_timeout"`
+	TemplateDelimiters           []string             `mapstructure:"template_delimiters" cty:"template_delimiters" hcl:"template_delimiters"`
+	TemplateScripts              *bool                `mapstructure:"template_scripts" cty:"template_scripts" hcl:"template_scripts"`
+	TranscriptDir                *string              `mapstructure:"transcript_dir" cty:"transcript_dir" hcl:"transcript_dir"`
+	UseFileParameter             *bool                `mapstructure:"use_file_parameter" cty:"use_file_parameter" hcl:"use_file_parameter"`
+	WorkingDirectory             *string              `mapstructure:"working_directory" cty:"working_directory" hcl:"working_directory"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -76,14 +105,30 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"binary":                          &hcldec.AttrSpec{Name: "binary", Type: cty.Bool, Required: false},
 		"remote_path":                     &hcldec.AttrSpec{Name: "remote_path", Type: cty.String, Required: false},
 		"execute_command":                 &hcldec.AttrSpec{Name: "execute_command", Type: cty.String, Required: false},
+		"download":                        &hcldec.BlockListSpec{TypeName: "download", Nested: hcldec.ObjectSpec((*FlatDownload)(nil).HCL2Spec())},
 		"elevated_env_var_format":         &hcldec.AttrSpec{Name: "elevated_env_var_format", Type: cty.String, Required: false},
 		"elevated_execute_command":        &hcldec.AttrSpec{Name: "elevated_execute_command", Type: cty.String, Required: false},
 		"elevated_password":               &hcldec.AttrSpec{Name: "elevated_password", Type: cty.String, Required: false},
 		"elevated_user":                   &hcldec.AttrSpec{Name: "elevated_user", Type: cty.String, Required: false},
+		"elevation_method":                &hcldec.AttrSpec{Name: "elevation_method", Type: cty.String, Required: false},
+		"error_action_preference":         &hcldec.AttrSpec{Name: "error_action_preference", Type: cty.String, Required: false},
+		"execution_policy":                &hcldec.AttrSpec{Name: "execution_policy", Type: cty.String, Required: false},
+		"execution_target":                &hcldec.AttrSpec{Name: "execution_target", Type: cty.String, Required: false},
+		"extra_arguments":                 &hcldec.AttrSpec{Name: "extra_arguments", Type: cty.List(cty.String), Required: false},
+		"lint":                            &hcldec.AttrSpec{Name: "lint", Type: cty.Bool, Required: false},
+		"lint_analyzer_is_enabled":        &hcldec.AttrSpec{Name: "lint_analyzer_is_enabled", Type: cty.Bool, Required: false},
+		"lint_pwsh_path":                  &hcldec.AttrSpec{Name: "lint_pwsh_path", Type: cty.String, Required: false},
+		"log_dir":                         &hcldec.AttrSpec{Name: "log_dir", Type: cty.String, Required: false},
+		"no_profile":                      &hcldec.AttrSpec{Name: "no_profile", Type: cty.Bool, Required: false},
 		"os_type":                         &hcldec.AttrSpec{Name: "os_type", Type: cty.String, Required: false},
+		"output_file":                     &hcldec.AttrSpec{Name: "output_file", Type: cty.String, Required: false},
+		"output_prefix":                   &hcldec.AttrSpec{Name: "output_prefix", Type: cty.String, Required: false},
+		"powershell_edition":              &hcldec.AttrSpec{Name: "powershell_edition", Type: cty.String, Required: false},
+		"progress_preference":             &hcldec.AttrSpec{Name: "progress_preference", Type: cty.String, Required: false},
 		"pwsh_autoupdate_command":         &hcldec.AttrSpec{Name: "pwsh_autoupdate_command", Type: cty.String, Required: false},
 		"pwsh_autoupdate_execute_command": &hcldec.AttrSpec{Name: "pwsh_autoupdate_execute_command", Type: cty.String, Required: false},
 		"pwsh_autoupdate_is_enabled":      &hcldec.AttrSpec{Name: "pwsh_autoupdate_is_enabled", Type: cty.Bool, Required: false},
+		"pwsh_path":                       &hcldec.AttrSpec{Name: "pwsh_path", Type: cty.String, Required: false},
 		"reboot_complete_command":         &hcldec.AttrSpec{Name: "reboot_complete_command", Type: cty.String, Required: false},
 		"reboot_initiate_command":         &hcldec.AttrSpec{Name: "reboot_initiate_command", Type: cty.String, Required: false},
 		"reboot_is_enabled":               &hcldec.AttrSpec{Name: "reboot_is_enabled", Type: cty.Bool, Required: false},
@@ -92,6 +137,383 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"reboot_validate_command":         &hcldec.AttrSpec{Name: "reboot_validate_command", Type: cty.String, Required: false},
 		"remote_env_var_path":             &hcldec.AttrSpec{Name: "remote_env_var_path", Type: cty.String, Required: false},
 		"remote_pwsh_autoupdate_path":     &hcldec.AttrSpec{Name: "remote_pwsh_autoupdate_path", Type: cty.String, Required: false},
+		"remote_transcript_path":          &hcldec.AttrSpec{Name: "remote_transcript_path", Type: cty.String, Required: false},
+		"run_as_execute_command":          &hcldec.AttrSpec{Name: "run_as_execute_command", Type: cty.String, Required: false},
+		"run_as_user":                     &hcldec.AttrSpec{Name: "run_as_user", Type: cty.String, Required: false},
+		"sensitive_env":                   &hcldec.AttrSpec{Name: "sensitive_env", Type: cty.Map(cty.String), Required: false},
+		"script_kill_command":             &hcldec.AttrSpec{Name: "script_kill_command", Type: cty.String, Required: false},
+		"script_sha256_manifest":          &hcldec.AttrSpec{Name: "script_sha256_manifest", Type: cty.String, Required: false},
+		"script_override":                 &hcldec.BlockListSpec{TypeName: "script_override", Nested: hcldec.ObjectSpec((*FlatScriptOverride)(nil).HCL2Spec())},
+		"script_timeout":                  &hcldec.AttrSpec{Name: "script_timeout", Type: cty.String, Required: false},
+		"template_delimiters":             &hcldec.AttrSpec{Name: "template_delimiters", Type: cty.List(cty.String), Required: false},
+		"template_scripts":                &hcldec.AttrSpec{Name: "template_scripts", Type: cty.Bool, Required: false},
+		"transcript_dir":                  &hcldec.AttrSpec{Name: "transcript_dir", Type: cty.String, Required: false},
+		"use_file_parameter":              &hcldec.AttrSpec{Name: "use_file_parameter", Type: cty.Bool, Required: false},
+		"working_directory":               &hcldec.AttrSpec{Name: "working_directory", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatDownload is an auto-generated flat version of Download.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatDownload struct {
+	Destination *string `mapstructure:"destination" cty:"destination" hcl:"destination"`
+	Source      *string `mapstructure:"source" cty:"source" hcl:"source"`
+}
+
+// FlatMapstructure returns a new FlatDownload.
+// FlatDownload is an auto-generated flat version of Download.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Download) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatDownload)
+}
+
+// HCL2Spec returns the hcl spec of a Download.
+// This spec is used by HCL to read the fields of Download.
+// The decoded values from this spec will then be applied to a FlatDownload.
+func (*FlatDownload) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"destination": &hcldec.AttrSpec{Name: "destination", Type: cty.String, Required: false},
+		"source":      &hcldec.AttrSpec{Name: "source", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatDscConfig is an auto-generated flat version of DscConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatDscConfig struct {
+	PackerBuildName              *string              `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType            *string              `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion            *string              `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                  *bool                `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                  *bool                `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError                *string              `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars               map[string]string    `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars          []string             `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	Inline                       []string             `cty:"inline" hcl:"inline"`
+	Script                       *string              `cty:"script" hcl:"script"`
+	Scripts                      []string             `cty:"scripts" hcl:"scripts"`
+	ValidExitCodes               []int                `mapstructure:"valid_exit_codes" cty:"valid_exit_codes" hcl:"valid_exit_codes"`
+	Vars                         []string             `mapstructure:"environment_vars" cty:"environment_vars" hcl:"environment_vars"`
+	Env                          map[string]string    `mapstructure:"env" cty:"env" hcl:"env"`
+	EnvVarFormat                 *string              `mapstructure:"env_var_format" cty:"env_var_format" hcl:"env_var_format"`
+	Binary                       *bool                `cty:"binary" hcl:"binary"`
+	RemotePath                   *string              `mapstructure:"remote_path" cty:"remote_path" hcl:"remote_path"`
+	ExecuteCommand               *string              `mapstructure:"execute_command" cty:"execute_command" hcl:"execute_command"`
+	Downloads                    []FlatDownload       `mapstructure:"download" cty:"download" hcl:"download"`
+	ElevatedEnvVarFormat         *string              `mapstructure:"elevated_env_var_format" cty:"elevated_env_var_format" hcl:"elevated_env_var_format"`
+	ElevatedExecuteCommand       *string              `mapstructure:"elevated_execute_command" cty:"elevated_execute_command" hcl:"elevated_execute_command"`
+	ElevatedPassword             *string              `mapstructure:"elevated_password" cty:"elevated_password" hcl:"elevated_password"`
+	ElevatedUser                 *string              `mapstructure:"elevated_user" cty:"elevated_user" hcl:"elevated_user"`
+	ElevationMethod              *string              `mapstructure:"elevation_method" cty:"elevation_method" hcl:"elevation_method"`
+	ErrorActionPreference        *string              `mapstructure:"error_action_preference" cty:"error_action_preference" hcl:"error_action_preference"`
+	ExecutionPolicy              *string              `mapstructure:"execution_policy" cty:"execution_policy" hcl:"execution_policy"`
+	ExecutionTarget              *string              `mapstructure:"execution_target" cty:"execution_target" hcl:"execution_target"`
+	ExtraArguments               []string             `mapstructure:"extra_arguments" cty:"extra_arguments" hcl:"extra_arguments"`
+	Lint                         *bool                `mapstructure:"lint" cty:"lint" hcl:"lint"`
+	LintAnalyzerIsEnabled        *bool                `mapstructure:"lint_analyzer_is_enabled" cty:"lint_analyzer_is_enabled" hcl:"lint_analyzer_is_enabled"`
+	LintPwshPath                 *string              `mapstructure:"lint_pwsh_path" cty:"lint_pwsh_path" hcl:"lint_pwsh_path"`
+	LogDir                       *string              `mapstructure:"log_dir" cty:"log_dir" hcl:"log_dir"`
+	NoProfile                    *bool                `mapstructure:"no_profile" cty:"no_profile" hcl:"no_profile"`
+	OsType                       *string              `mapstructure:"os_type" cty:"os_type" hcl:"os_type"`
+	OutputFile                   *string              `mapstructure:"output_file" cty:"output_file" hcl:"output_file"`
+	OutputPrefix                 *string              `mapstructure:"output_prefix" cty:"output_prefix" hcl:"output_prefix"`
+	PowershellEdition            *string              `mapstructure:"powershell_edition" cty:"powershell_edition" hcl:"powershell_edition"`
+	ProgressPreference           *string              `mapstructure:"progress_preference" cty:"progress_preference" hcl:"progress_preference"`
+	PwshAutoUpdateCommand        *string              `mapstructure:"pwsh_autoupdate_command" cty:"pwsh_autoupdate_command" hcl:"pwsh_autoupdate_command"`
+	PwshAutoUpdateExecuteCommand *string              `mapstructure:"pwsh_autoupdate_execute_command" cty:"pwsh_autoupdate_execute_command" hcl:"pwsh_autoupdate_execute_command"`
+	PwshAutoUpdateIsEnabled      *bool                `mapstructure:"pwsh_autoupdate_is_enabled" cty:"pwsh_autoupdate_is_enabled" hcl:"pwsh_autoupdate_is_enabled"`
+	PwshPath                     *string              `mapstructure:"pwsh_path" cty:"pwsh_path" hcl:"pwsh_path"`
+	RebootCompleteCommand        *string              `mapstructure:"reboot_complete_command" cty:"reboot_complete_command" hcl:"reboot_complete_command"`
+	RebootInitiateCommand        *string              `mapstructure:"reboot_initiate_command" cty:"reboot_initiate_command" hcl:"reboot_initiate_command"`
+	RebootIsEnabled              *bool                `mapstructure:"reboot_is_enabled" cty:"reboot_is_enabled" hcl:"reboot_is_enabled"`
+	RebootPendingCommand         *string              `mapstructure:"reboot_pending_command" cty:"reboot_pending_command" hcl:"reboot_pending_command"`
+	RebootProgressCommand        *string              `mapstructure:"reboot_progress_command" cty:"reboot_progress_command" hcl:"reboot_progress_command"`
+	RebootValidateCommand        *string              `mapstructure:"reboot_validate_command" cty:"reboot_validate_command" hcl:"reboot_validate_command"`
+	RemoteEnvVarPath             *string              `mapstructure:"remote_env_var_path" cty:"remote_env_var_path" hcl:"remote_env_var_path"`
+	RemotePwshAutoUpdatePath     *string              `mapstructure:"remote_pwsh_autoupdate_path" cty:"remote_pwsh_autoupdate_path" hcl:"remote_pwsh_autoupdate_path"`
+	RemoteTranscriptPath         *string              `mapstructure:"remote_transcript_path" cty:"remote_transcript_path" hcl:"remote_transcript_path"`
+	RunAsExecuteCommand          *string              `mapstructure:"run_as_execute_command" cty:"run_as_execute_command" hcl:"run_as_execute_command"`
+	RunAsUser                    *string              `mapstructure:"run_as_user" cty:"run_as_user" hcl:"run_as_user"`
+	SensitiveEnv                 map[string]string    `mapstructure:"sensitive_env" cty:"sensitive_env" hcl:"sensitive_env"`
+	ScriptKillCommand            *string              `mapstructure:"script_kill_command" cty:"script_kill_command" hcl:"script_kill_command"`
+	ScriptManifestPath           *string              `mapstructure:"script_sha256_manifest" cty:"script_sha256_manifest" hcl:"script_sha256_manifest"`
+	ScriptOverrides              []FlatScriptOverride `mapstructure:"script_override" cty:"script_override" hcl:"script_override"`
+	ScriptTimeout                *string              `mapstructure:"script_timeout" cty:"script_timeout" hcl:"script_timeout"`
+	TemplateDelimiters           []string             `mapstructure:"template_delimiters" cty:"template_delimiters" hcl:"template_delimiters"`
+	TemplateScripts              *bool                `mapstructure:"template_scripts" cty:"template_scripts" hcl:"template_scripts"`
+	TranscriptDir                *string              `mapstructure:"transcript_dir" cty:"transcript_dir" hcl:"transcript_dir"`
+	UseFileParameter             *bool                `mapstructure:"use_file_parameter" cty:"use_file_parameter" hcl:"use_file_parameter"`
+	WorkingDirectory             *string              `mapstructure:"working_directory" cty:"working_directory" hcl:"working_directory"`
+	Configuration                *string              `mapstructure:"configuration" cty:"configuration" hcl:"configuration"`
+	Modules                      []string             `mapstructure:"modules" cty:"modules" hcl:"modules"`
+	RebootLimit                  *int                 `mapstructure:"reboot_limit" cty:"reboot_limit" hcl:"reboot_limit"`
+	RemoteDscPath                *string              `mapstructure:"remote_dsc_path" cty:"remote_dsc_path" hcl:"remote_dsc_path"`
+}
+
+// FlatMapstructure returns a new FlatDscConfig.
+// FlatDscConfig is an auto-generated flat version of DscConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*DscConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatDscConfig)
+}
+
+// HCL2Spec returns the hcl spec of a DscConfig.
+// This spec is used by HCL to read the fields of DscConfig.
+// The decoded values from this spec will then be applied to a FlatDscConfig.
+func (*FlatDscConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"packer_build_name":               &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":             &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":             &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                    &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                    &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":                 &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":           &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":      &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"inline":                          &hcldec.AttrSpec{Name: "inline", Type: cty.List(cty.String), Required: false},
+		"script":                          &hcldec.AttrSpec{Name: "script", Type: cty.String, Required: false},
+		"scripts":                         &hcldec.AttrSpec{Name: "scripts", Type: cty.List(cty.String), Required: false},
+		"valid_exit_codes":                &hcldec.AttrSpec{Name: "valid_exit_codes", Type: cty.List(cty.Number), Required: false},
+		"environment_vars":                &hcldec.AttrSpec{Name: "environment_vars", Type: cty.List(cty.String), Required: false},
+		"env":                             &hcldec.AttrSpec{Name: "env", Type: cty.Map(cty.String), Required: false},
+		"env_var_format":                  &hcldec.AttrSpec{Name: "env_var_format", Type: cty.String, Required: false},
+		"binary":                          &hcldec.AttrSpec{Name: "binary", Type: cty.Bool, Required: false},
+		"remote_path":                     &hcldec.AttrSpec{Name: "remote_path", Type: cty.String, Required: false},
+		"execute_command":                 &hcldec.AttrSpec{Name: "execute_command", Type: cty.String, Required: false},
+		"download":                        &hcldec.BlockListSpec{TypeName: "download", Nested: hcldec.ObjectSpec((*FlatDownload)(nil).HCL2Spec())},
+		"elevated_env_var_format":         &hcldec.AttrSpec{Name: "elevated_env_var_format", Type: cty.String, Required: false},
+		"elevated_execute_command":        &hcldec.AttrSpec{Name: "elevated_execute_command", Type: cty.String, Required: false},
+		"elevated_password":               &hcldec.AttrSpec{Name: "elevated_password", Type: cty.String, Required: false},
+		"elevated_user":                   &hcldec.AttrSpec{Name: "elevated_user", Type: cty.String, Required: false},
+		"elevation_method":                &hcldec.AttrSpec{Name: "elevation_method", Type: cty.String, Required: false},
+		"error_action_preference":         &hcldec.AttrSpec{Name: "error_action_preference", Type: cty.String, Required: false},
+		"execution_policy":                &hcldec.AttrSpec{Name: "execution_policy", Type: cty.String, Required: false},
+		"execution_target":                &hcldec.AttrSpec{Name: "execution_target", Type: cty.String, Required: false},
+		"extra_arguments":                 &hcldec.AttrSpec{Name: "extra_arguments", Type: cty.List(cty.String), Required: false},
+		"lint":                            &hcldec.AttrSpec{Name: "lint", Type: cty.Bool, Required: false},
+		"lint_analyzer_is_enabled":        &hcldec.AttrSpec{Name: "lint_analyzer_is_enabled", Type: cty.Bool, Required: false},
+		"lint_pwsh_path":                  &hcldec.AttrSpec{Name: "lint_pwsh_path", Type: cty.String, Required: false},
+		"log_dir":                         &hcldec.AttrSpec{Name: "log_dir", Type: cty.String, Required: false},
+		"no_profile":                      &hcldec.AttrSpec{Name: "no_profile", Type: cty.Bool, Required: false},
+		"os_type":                         &hcldec.AttrSpec{Name: "os_type", Type: cty.String, Required: false},
+		"output_file":                     &hcldec.AttrSpec{Name: "output_file", Type: cty.String, Required: false},
+		"output_prefix":                   &hcldec.AttrSpec{Name: "output_prefix", Type: cty.String, Required: false},
+		"powershell_edition":              &hcldec.AttrSpec{Name: "powershell_edition", Type: cty.String, Required: false},
+		"progress_preference":             &hcldec.AttrSpec{Name: "progress_preference", Type: cty.String, Required: false},
+		"pwsh_autoupdate_command":         &hcldec.AttrSpec{Name: "pwsh_autoupdate_command", Type: cty.String, Required: false},
+		"pwsh_autoupdate_execute_command": &hcldec.AttrSpec{Name: "pwsh_autoupdate_execute_command", Type: cty.String, Required: false},
+		"pwsh_autoupdate_is_enabled":      &hcldec.AttrSpec{Name: "pwsh_autoupdate_is_enabled", Type: cty.Bool, Required: false},
+		"pwsh_path":                       &hcldec.AttrSpec{Name: "pwsh_path", Type: cty.String, Required: false},
+		"reboot_complete_command":         &hcldec.AttrSpec{Name: "reboot_complete_command", Type: cty.String, Required: false},
+		"reboot_initiate_command":         &hcldec.AttrSpec{Name: "reboot_initiate_command", Type: cty.String, Required: false},
+		"reboot_is_enabled":               &hcldec.AttrSpec{Name: "reboot_is_enabled", Type: cty.Bool, Required: false},
+		"reboot_pending_command":          &hcldec.AttrSpec{Name: "reboot_pending_command", Type: cty.String, Required: false},
+		"reboot_progress_command":         &hcldec.AttrSpec{Name: "reboot_progress_command", Type: cty.String, Required: false},
+		"reboot_validate_command":         &hcldec.AttrSpec{Name: "reboot_validate_command", Type: cty.String, Required: false},
+		"remote_env_var_path":             &hcldec.AttrSpec{Name: "remote_env_var_path", Type: cty.String, Required: false},
+		"remote_pwsh_autoupdate_path":     &hcldec.AttrSpec{Name: "remote_pwsh_autoupdate_path", Type: cty.String, Required: false},
+		"remote_transcript_path":          &hcldec.AttrSpec{Name: "remote_transcript_path", Type: cty.String, Required: false},
+		"run_as_execute_command":          &hcldec.AttrSpec{Name: "run_as_execute_command", Type: cty.String, Required: false},
+		"run_as_user":                     &hcldec.AttrSpec{Name: "run_as_user", Type: cty.String, Required: false},
+		"sensitive_env":                   &hcldec.AttrSpec{Name: "sensitive_env", Type: cty.Map(cty.String), Required: false},
+		"script_kill_command":             &hcldec.AttrSpec{Name: "script_kill_command", Type: cty.String, Required: false},
+		"script_sha256_manifest":          &hcldec.AttrSpec{Name: "script_sha256_manifest", Type: cty.String, Required: false},
+		"script_override":                 &hcldec.BlockListSpec{TypeName: "script_override", Nested: hcldec.ObjectSpec((*FlatScriptOverride)(nil).HCL2Spec())},
+		"script_timeout":                  &hcldec.AttrSpec{Name: "script_timeout", Type: cty.String, Required: false},
+		"template_delimiters":             &hcldec.AttrSpec{Name: "template_delimiters", Type: cty.List(cty.String), Required: false},
+		"template_scripts":                &hcldec.AttrSpec{Name: "template_scripts", Type: cty.Bool, Required: false},
+		"transcript_dir":                  &hcldec.AttrSpec{Name: "transcript_dir", Type: cty.String, Required: false},
+		"use_file_parameter":              &hcldec.AttrSpec{Name: "use_file_parameter", Type: cty.Bool, Required: false},
+		"working_directory":               &hcldec.AttrSpec{Name: "working_directory", Type: cty.String, Required: false},
+		"configuration":                   &hcldec.AttrSpec{Name: "configuration", Type: cty.String, Required: false},
+		"modules":                         &hcldec.AttrSpec{Name: "modules", Type: cty.List(cty.String), Required: false},
+		"reboot_limit":                    &hcldec.AttrSpec{Name: "reboot_limit", Type: cty.Number, Required: false},
+		"remote_dsc_path":                 &hcldec.AttrSpec{Name: "remote_dsc_path", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatPesterConfig is an auto-generated flat version of PesterConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatPesterConfig struct {
+	PackerBuildName              *string              `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType            *string              `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion            *string              `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                  *bool                `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                  *bool                `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError                *string              `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars               map[string]string    `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars          []string             `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	Inline                       []string             `cty:"inline" hcl:"inline"`
+	Script                       *string              `cty:"script" hcl:"script"`
+	Scripts                      []string             `cty:"scripts" hcl:"scripts"`
+	ValidExitCodes               []int                `mapstructure:"valid_exit_codes" cty:"valid_exit_codes" hcl:"valid_exit_codes"`
+	Vars                         []string             `mapstructure:"environment_vars" cty:"environment_vars" hcl:"environment_vars"`
+	Env                          map[string]string    `mapstructure:"env" cty:"env" hcl:"env"`
+	EnvVarFormat                 *string              `mapstructure:"env_var_format" cty:"env_var_format" hcl:"env_var_format"`
+	Binary                       *bool                `cty:"binary" hcl:"binary"`
+	RemotePath                   *string              `mapstructure:"remote_path" cty:"remote_path" hcl:"remote_path"`
+	ExecuteCommand               *string              `mapstructure:"execute_command" cty:"execute_command" hcl:"execute_command"`
+	Downloads                    []FlatDownload       `mapstructure:"download" cty:"download" hcl:"download"`
+	ElevatedEnvVarFormat         *string              `mapstructure:"elevated_env_var_format" cty:"elevated_env_var_format" hcl:"elevated_env_var_format"`
+	ElevatedExecuteCommand       *string              `mapstructure:"elevated_execute_command" cty:"elevated_execute_command" hcl:"elevated_execute_command"`
+	ElevatedPassword             *string              `mapstructure:"elevated_password" cty:"elevated_password" hcl:"elevated_password"`
+	ElevatedUser                 *string              `mapstructure:"elevated_user" cty:"elevated_user" hcl:"elevated_user"`
+	ElevationMethod              *string              `mapstructure:"elevation_method" cty:"elevation_method" hcl:"elevation_method"`
+	ErrorActionPreference        *string              `mapstructure:"error_action_preference" cty:"error_action_preference" hcl:"error_action_preference"`
+	ExecutionPolicy              *string              `mapstructure:"execution_policy" cty:"execution_policy" hcl:"execution_policy"`
+	ExecutionTarget              *string              `mapstructure:"execution_target" cty:"execution_target" hcl:"execution_target"`
+	ExtraArguments               []string             `mapstructure:"extra_arguments" cty:"extra_arguments" hcl:"extra_arguments"`
+	Lint                         *bool                `mapstructure:"lint" cty:"lint" hcl:"lint"`
+	LintAnalyzerIsEnabled        *bool                `mapstructure:"lint_analyzer_is_enabled" cty:"lint_analyzer_is_enabled" hcl:"lint_analyzer_is_enabled"`
+	LintPwshPath                 *string              `mapstructure:"lint_pwsh_path" cty:"lint_pwsh_path" hcl:"lint_pwsh_path"`
+	LogDir                       *string              `mapstructure:"log_dir" cty:"log_dir" hcl:"log_dir"`
+	NoProfile                    *bool                `mapstructure:"no_profile" cty:"no_profile" hcl:"no_profile"`
+	OsType                       *string              `mapstructure:"os_type" cty:"os_type" hcl:"os_type"`
+	OutputFile                   *string              `mapstructure:"output_file" cty:"output_file" hcl:"output_file"`
+	OutputPrefix                 *string              `mapstructure:"output_prefix" cty:"output_prefix" hcl:"output_prefix"`
+	PowershellEdition            *string              `mapstructure:"powershell_edition" cty:"powershell_edition" hcl:"powershell_edition"`
+	ProgressPreference           *string              `mapstructure:"progress_preference" cty:"progress_preference" hcl:"progress_preference"`
+	PwshAutoUpdateCommand        *string              `mapstructure:"pwsh_autoupdate_command" cty:"pwsh_autoupdate_command" hcl:"pwsh_autoupdate_command"`
+	PwshAutoUpdateExecuteCommand *string              `mapstructure:"pwsh_autoupdate_execute_command" cty:"pwsh_autoupdate_execute_command" hcl:"pwsh_autoupdate_execute_command"`
+	PwshAutoUpdateIsEnabled      *bool                `mapstructure:"pwsh_autoupdate_is_enabled" cty:"pwsh_autoupdate_is_enabled" hcl:"pwsh_autoupdate_is_enabled"`
+	PwshPath                     *string              `mapstructure:"pwsh_path" cty:"pwsh_path" hcl:"pwsh_path"`
+	RebootCompleteCommand        *string              `mapstructure:"reboot_complete_command" cty:"reboot_complete_command" hcl:"reboot_complete_command"`
+	RebootInitiateCommand        *string              `mapstructure:"reboot_initiate_command" cty:"reboot_initiate_command" hcl:"reboot_initiate_command"`
+	RebootIsEnabled              *bool                `mapstructure:"reboot_is_enabled" cty:"reboot_is_enabled" hcl:"reboot_is_enabled"`
+	RebootPendingCommand         *string              `mapstructure:"reboot_pending_command" cty:"reboot_pending_command" hcl:"reboot_pending_command"`
+	RebootProgressCommand        *string              `mapstructure:"reboot_progress_command" cty:"reboot_progress_command" hcl:"reboot_progress_command"`
+	RebootValidateCommand        *string              `mapstructure:"reboot_validate_command" cty:"reboot_validate_command" hcl:"reboot_validate_command"`
+	RemoteEnvVarPath             *string              `mapstructure:"remote_env_var_path" cty:"remote_env_var_path" hcl:"remote_env_var_path"`
+	RemotePwshAutoUpdatePath     *string              `mapstructure:"remote_pwsh_autoupdate_path" cty:"remote_pwsh_autoupdate_path" hcl:"remote_pwsh_autoupdate_path"`
+	RemoteTranscriptPath         *string              `mapstructure:"remote_transcript_path" cty:"remote_transcript_path" hcl:"remote_transcript_path"`
+	RunAsExecuteCommand          *string              `mapstructure:"run_as_execute_command" cty:"run_as_execute_command" hcl:"run_as_execute_command"`
+	RunAsUser                    *string              `mapstructure:"run_as_user" cty:"run_as_user" hcl:"run_as_user"`
+	SensitiveEnv                 map[string]string    `mapstructure:"sensitive_env" cty:"sensitive_env" hcl:"sensitive_env"`
+	ScriptKillCommand            *string              `mapstructure:"script_kill_command" cty:"script_kill_command" hcl:"script_kill_command"`
+	ScriptManifestPath           *string              `mapstructure:"script_sha256_manifest" cty:"script_sha256_manifest" hcl:"script_sha256_manifest"`
+	ScriptOverrides              []FlatScriptOverride `mapstructure:"script_override" cty:"script_override" hcl:"script_override"`
+	ScriptTimeout                *string              `mapstructure:"script_timeout" cty:"script_timeout" hcl:"script_timeout"`
+	TemplateDelimiters           []string             `mapstructure:"template_delimiters" cty:"template_delimiters" hcl:"template_delimiters"`
+	TemplateScripts              *bool                `mapstructure:"template_scripts" cty:"template_scripts" hcl:"template_scripts"`
+	TranscriptDir                *string              `mapstructure:"transcript_dir" cty:"transcript_dir" hcl:"transcript_dir"`
+	UseFileParameter             *bool                `mapstructure:"use_file_parameter" cty:"use_file_parameter" hcl:"use_file_parameter"`
+	WorkingDirectory             *string              `mapstructure:"working_directory" cty:"working_directory" hcl:"working_directory"`
+	PesterVersion                *string              `mapstructure:"pester_version" cty:"pester_version" hcl:"pester_version"`
+	RemoteTestPath               *string              `mapstructure:"remote_test_path" cty:"remote_test_path" hcl:"remote_test_path"`
+	ResultFormat                 *string              `mapstructure:"result_format" cty:"result_format" hcl:"result_format"`
+	ResultPath                   *string              `mapstructure:"result_path" cty:"result_path" hcl:"result_path"`
+	Tests                        []string             `mapstructure:"tests" cty:"tests" hcl:"tests"`
+}
+
+// FlatMapstructure returns a new FlatPesterConfig.
+// FlatPesterConfig is an auto-generated flat version of PesterConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*PesterConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatPesterConfig)
+}
+
+// HCL2Spec returns the hcl spec of a PesterConfig.
+// This spec is used by HCL to read the fields of PesterConfig.
+// The decoded values from this spec will then be applied to a FlatPesterConfig.
+func (*FlatPesterConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"packer_build_name":               &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":             &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":             &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                    &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                    &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":                 &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":           &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":      &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"inline":                          &hcldec.AttrSpec{Name: "inline", Type: cty.List(cty.String), Required: false},
+		"script":                          &hcldec.AttrSpec{Name: "script", Type: cty.String, Required: false},
+		"scripts":                         &hcldec.AttrSpec{Name: "scripts", Type: cty.List(cty.String), Required: false},
+		"valid_exit_codes":                &hcldec.AttrSpec{Name: "valid_exit_codes", Type: cty.List(cty.Number), Required: false},
+		"environment_vars":                &hcldec.AttrSpec{Name: "environment_vars", Type: cty.List(cty.String), Required: false},
+		"env":                             &hcldec.AttrSpec{Name: "env", Type: cty.Map(cty.String), Required: false},
+		"env_var_format":                  &hcldec.AttrSpec{Name: "env_var_format", Type: cty.String, Required: false},
+		"binary":                          &hcldec.AttrSpec{Name: "binary", Type: cty.Bool, Required: false},
+		"remote_path":                     &hcldec.AttrSpec{Name: "remote_path", Type: cty.String, Required: false},
+		"execute_command":                 &hcldec.AttrSpec{Name: "execute_command", Type: cty.String, Required: false},
+		"download":                        &hcldec.BlockListSpec{TypeName: "download", Nested: hcldec.ObjectSpec((*FlatDownload)(nil).HCL2Spec())},
+		"elevated_env_var_format":         &hcldec.AttrSpec{Name: "elevated_env_var_format", Type: cty.String, Required: false},
+		"elevated_execute_command":        &hcldec.AttrSpec{Name: "elevated_execute_command", Type: cty.String, Required: false},
+		"elevated_password":               &hcldec.AttrSpec{Name: "elevated_password", Type: cty.String, Required: false},
+		"elevated_user":                   &hcldec.AttrSpec{Name: "elevated_user", Type: cty.String, Required: false},
+		"elevation_method":                &hcldec.AttrSpec{Name: "elevation_method", Type: cty.String, Required: false},
+		"error_action_preference":         &hcldec.AttrSpec{Name: "error_action_preference", Type: cty.String, Required: false},
+		"execution_policy":                &hcldec.AttrSpec{Name: "execution_policy", Type: cty.String, Required: false},
+		"execution_target":                &hcldec.AttrSpec{Name: "execution_target", Type: cty.String, Required: false},
+		"extra_arguments":                 &hcldec.AttrSpec{Name: "extra_arguments", Type: cty.List(cty.String), Required: false},
+		"lint":                            &hcldec.AttrSpec{Name: "lint", Type: cty.Bool, Required: false},
+		"lint_analyzer_is_enabled":        &hcldec.AttrSpec{Name: "lint_analyzer_is_enabled", Type: cty.Bool, Required: false},
+		"lint_pwsh_path":                  &hcldec.AttrSpec{Name: "lint_pwsh_path", Type: cty.String, Required: false},
+		"log_dir":                         &hcldec.AttrSpec{Name: "log_dir", Type: cty.String, Required: false},
+		"no_profile":                      &hcldec.AttrSpec{Name: "no_profile", Type: cty.Bool, Required: false},
+		"os_type":                         &hcldec.AttrSpec{Name: "os_type", Type: cty.String, Required: false},
+		"output_file":                     &hcldec.AttrSpec{Name: "output_file", Type: cty.String, Required: false},
+		"output_prefix":                   &hcldec.AttrSpec{Name: "output_prefix", Type: cty.String, Required: false},
+		"powershell_edition":              &hcldec.AttrSpec{Name: "powershell_edition", Type: cty.String, Required: false},
+		"progress_preference":             &hcldec.AttrSpec{Name: "progress_preference", Type: cty.String, Required: false},
+		"pwsh_autoupdate_command":         &hcldec.AttrSpec{Name: "pwsh_autoupdate_command", Type: cty.String, Required: false},
+		"pwsh_autoupdate_execute_command": &hcldec.AttrSpec{Name: "pwsh_autoupdate_execute_command", Type: cty.String, Required: false},
+		"pwsh_autoupdate_is_enabled":      &hcldec.AttrSpec{Name: "pwsh_autoupdate_is_enabled", Type: cty.Bool, Required: false},
+		"pwsh_path":                       &hcldec.AttrSpec{Name: "pwsh_path", Type: cty.String, Required: false},
+		"reboot_complete_command":         &hcldec.AttrSpec{Name: "reboot_complete_command", Type: cty.String, Required: false},
+		"reboot_initiate_command":         &hcldec.AttrSpec{Name: "reboot_initiate_command", Type: cty.String, Required: false},
+		"reboot_is_enabled":               &hcldec.AttrSpec{Name: "reboot_is_enabled", Type: cty.Bool, Required: false},
+		"reboot_pending_command":          &hcldec.AttrSpec{Name: "reboot_pending_command", Type: cty.String, Required: false},
+		"reboot_progress_command":         &hcldec.AttrSpec{Name: "reboot_progress_command", Type: cty.String, Required: false},
+		"reboot_validate_command":         &hcldec.AttrSpec{Name: "reboot_validate_command", Type: cty.String, Required: false},
+		"remote_env_var_path":             &hcldec.AttrSpec{Name: "remote_env_var_path", Type: cty.String, Required: false},
+		"remote_pwsh_autoupdate_path":     &hcldec.AttrSpec{Name: "remote_pwsh_autoupdate_path", Type: cty.String, Required: false},
+		"remote_transcript_path":          &hcldec.AttrSpec{Name: "remote_transcript_path", Type: cty.String, Required: false},
+		"run_as_execute_command":          &hcldec.AttrSpec{Name: "run_as_execute_command", Type: cty.String, Required: false},
+		"run_as_user":                     &hcldec.AttrSpec{Name: "run_as_user", Type: cty.String, Required: false},
+		"sensitive_env":                   &hcldec.AttrSpec{Name: "sensitive_env", Type: cty.Map(cty.String), Required: false},
+		"script_kill_command":             &hcldec.AttrSpec{Name: "script_kill_command", Type: cty.String, Required: false},
+		"script_sha256_manifest":          &hcldec.AttrSpec{Name: "script_sha256_manifest", Type: cty.String, Required: false},
+		"script_override":                 &hcldec.BlockListSpec{TypeName: "script_override", Nested: hcldec.ObjectSpec((*FlatScriptOverride)(nil).HCL2Spec())},
+		"script_timeout":                  &hcldec.AttrSpec{Name: "script_timeout", Type: cty.String, Required: false},
+		"template_delimiters":             &hcldec.AttrSpec{Name: "template_delimiters", Type: cty.List(cty.String), Required: false},
+		"template_scripts":                &hcldec.AttrSpec{Name: "template_scripts", Type: cty.Bool, Required: false},
+		"transcript_dir":                  &hcldec.AttrSpec{Name: "transcript_dir", Type: cty.String, Required: false},
+		"use_file_parameter":              &hcldec.AttrSpec{Name: "use_file_parameter", Type: cty.Bool, Required: false},
+		"working_directory":               &hcldec.AttrSpec{Name: "working_directory", Type: cty.String, Required: false},
+		"pester_version":                  &hcldec.AttrSpec{Name: "pester_version", Type: cty.String, Required: false},
+		"remote_test_path":                &hcldec.AttrSpec{Name: "remote_test_path", Type: cty.String, Required: false},
+		"result_format":                   &hcldec.AttrSpec{Name: "result_format", Type: cty.String, Required: false},
+		"result_path":                     &hcldec.AttrSpec{Name: "result_path", Type: cty.String, Required: false},
+		"tests":                           &hcldec.AttrSpec{Name: "tests", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatScriptOverride is an auto-generated flat version of ScriptOverride.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatScriptOverride struct {
+	Path             *string `mapstructure:"path" cty:"path" hcl:"path"`
+	Timeout          *string `mapstructure:"timeout" cty:"timeout" hcl:"timeout"`
+	WorkingDirectory *string `mapstructure:"working_directory" cty:"working_directory" hcl:"working_directory"`
+}
+
+// FlatMapstructure returns a new FlatScriptOverride.
+// FlatScriptOverride is an auto-generated flat version of ScriptOverride.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ScriptOverride) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatScriptOverride)
+}
+
+// HCL2Spec returns the hcl spec of a ScriptOverride.
+// This spec is used by HCL to read the fields of ScriptOverride.
+// The decoded values from this spec will then be applied to a FlatScriptOverride.
+func (*FlatScriptOverride) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"path":              &hcldec.AttrSpec{Name: "path", Type: cty.String, Required: false},
+		"timeout":           &hcldec.AttrSpec{Name: "timeout", Type: cty.String, Required: false},
+		"working_directory": &hcldec.AttrSpec{Name: "working_directory", Type: cty.String, Required: false},
 	}
 	return s
 }
@@ -0,0 +1,107 @@
+package pwsh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	_ "embed"
+
+	"github.com/hashicorp/packer-plugin-sdk/acctest"
+)
+
+//go:embed test-fixtures/autoupdate.pkr.hcl
+var testAccAutoUpdateTemplate string
+
+//go:embed test-fixtures/env.pkr.hcl
+var testAccEnvTemplate string
+
+//go:embed test-fixtures/exit_codes.pkr.hcl
+var testAccExitCodesTemplate string
+
+//go:embed test-fixtures/exit_codes_invalid.pkr.hcl
+var testAccExitCodesInvalidTemplate string
+
+//go:embed test-fixtures/inline.pkr.hcl
+var testAccInlineTemplate string
+
+//go:embed test-fixtures/scripts.pkr.hcl
+var testAccScriptsTemplate string
+
+func testAccCheck(expectedExitCode int, patterns ...string) func(*exec.Cmd, string) error {
+	return func(buildCommand *exec.Cmd, logfile string) error {
+		if nil == buildCommand.ProcessState {
+			return fmt.Errorf("packer did not run; logfile: %s", logfile)
+		} else if exitCode := buildCommand.ProcessState.ExitCode(); expectedExitCode != exitCode {
+			return fmt.Errorf("expected packer to exit with %d, got %d; logfile: %s", expectedExitCode, exitCode, logfile)
+		}
+
+		if logs, e := os.ReadFile(logfile); nil != e {
+			return fmt.Errorf("unable to read %s: %s", logfile, e)
+		} else {
+			for _, pattern := range patterns {
+				if !regexp.MustCompile(pattern).Match(logs) {
+					return fmt.Errorf("expected the logs to match %q; logfile: %s", pattern, logfile)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestAccProvisioner_AutoUpdate(t *testing.T) {
+	if "" == os.Getenv("PKR_VAR_pwsh_mirror_url") {
+		t.Skip("the auto update acceptance test requires PKR_VAR_pwsh_mirror_url to point at a local powershell package mirror")
+	}
+
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check:    testAccCheck(0, `Resolved PowerShell installation`, `packer-acc-autoupdate`),
+		Init:     true,
+		Name:     "pwsh_provisioner_autoupdate_test",
+		Template: testAccAutoUpdateTemplate,
+		Type:     "pwsh",
+	})
+}
+func TestAccProvisioner_Env(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check:    testAccCheck(0, `packer-acc-env:hello world\|it's quoted\|pwsh`),
+		Name:     "pwsh_provisioner_env_test",
+		Template: testAccEnvTemplate,
+		Type:     "pwsh",
+	})
+}
+func TestAccProvisioner_ExitCodes(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check:    testAccCheck(0, `packer-acc-exit-code`, `Provisioning with pwsh; exit code: 3`),
+		Name:     "pwsh_provisioner_exit_codes_test",
+		Template: testAccExitCodesTemplate,
+		Type:     "pwsh",
+	})
+}
+func TestAccProvisioner_ExitCodesInvalid(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check:    testAccCheck(1, `Script exited with non-zero exit status: 5`),
+		Name:     "pwsh_provisioner_exit_codes_invalid_test",
+		Template: testAccExitCodesInvalidTemplate,
+		Type:     "pwsh",
+	})
+}
+func TestAccProvisioner_Inline(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check:    testAccCheck(0, `packer-acc-inline`),
+		Name:     "pwsh_provisioner_inline_test",
+		Template: testAccInlineTemplate,
+		Type:     "pwsh",
+	})
+}
+func TestAccProvisioner_Scripts(t *testing.T) {
+	acctest.TestPlugin(t, &acctest.PluginTestCase{
+		Check:    testAccCheck(0, `packer-acc-script:first`, `packer-acc-script:second`),
+		Name:     "pwsh_provisioner_scripts_test",
+		Template: testAccScriptsTemplate,
+		Type:     "pwsh",
+	})
+}
@@ -0,0 +1,1117 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testProvisioner(t *testing.T, config map[string]interface{}, rules ...*fakeCommandRule) (*Provisioner, *fakeCommunicator) {
+	p := new(Provisioner)
+
+	if e := p.Prepare(config); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	communicator := newFakeCommunicator(rules...)
+	generatedData := map[string]interface{}{
+		"PwshPath":         "pwsh",
+		"WorkingDirectory": p.config.WorkingDirectory,
+	}
+
+	p.communicator = communicator
+	p.config.ctx.Data = generatedData
+	p.generatedData = generatedData
+	p.remoteScriptPaths = make(map[string]string)
+
+	return p, communicator
+}
+func testRebootPollInterval(t *testing.T) {
+	originalRebootPollInterval := rebootPollInterval
+	rebootPollInterval = time.Millisecond
+
+	t.Cleanup(func() {
+		rebootPollInterval = originalRebootPollInterval
+	})
+}
+
+func TestProvisioner_ExecuteScriptCollection(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          map[string]interface{}
+		rules           []*fakeCommandRule
+		isErrorExpected bool
+		isKillExpected  bool
+		expectedOutputs map[string]string
+	}{
+		{
+			name:   "success",
+			config: map[string]interface{}{},
+			rules: []*fakeCommandRule{
+				{responses: []fakeCommandResponse{{stdout: "Hello, world!\n"}}},
+			},
+		},
+		{
+			name:            "invalid exit code",
+			config:          map[string]interface{}{},
+			rules:           []*fakeCommandRule{{responses: []fakeCommandResponse{{exitCode: 3}}}},
+			isErrorExpected: true,
+		},
+		{
+			name:   "valid exit code",
+			config: map[string]interface{}{"valid_exit_codes": []int{0, 3}},
+			rules:  []*fakeCommandRule{{responses: []fakeCommandResponse{{exitCode: 3}}}},
+		},
+		{
+			name:            "disconnect",
+			config:          map[string]interface{}{},
+			rules:           []*fakeCommandRule{{responses: []fakeCommandResponse{{disconnect: true}}}},
+			isErrorExpected: true,
+		},
+		{
+			name:   "error record",
+			config: map[string]interface{}{},
+			rules: []*fakeCommandRule{
+				{responses: []fakeCommandResponse{{
+					exitCode: 1,
+					stderr:   `packer-error:{"Category":"NotSpecified: (:) [], RuntimeException","Line":1,"Message":"Boom","Position":"At line:1","ScriptName":"","ScriptStackTrace":"at <ScriptBlock>"}` + "\n",
+				}}},
+			},
+			isErrorExpected: true,
+		},
+		{
+			name:   "outputs",
+			config: map[string]interface{}{},
+			rules: []*fakeCommandRule{
+				{responses: []fakeCommandResponse{{stdout: "packer-output:ImageVersion=1.2.3\nunrelated\n"}}},
+			},
+			expectedOutputs: map[string]string{"PwshOutput_ImageVersion": "1.2.3"},
+		},
+		{
+			name:   "invalid output name",
+			config: map[string]interface{}{},
+			rules: []*fakeCommandRule{
+				{responses: []fakeCommandResponse{{stdout: "packer-output:Image Version=1.2.3\n"}}},
+			},
+			isErrorExpected: true,
+		},
+		{
+			name:   "timeout",
+			config: map[string]interface{}{"script_timeout": "50ms"},
+			rules: []*fakeCommandRule{
+				{command: "pgrep", responses: []fakeCommandResponse{{}}},
+				{responses: []fakeCommandResponse{{delay: time.Minute}}},
+			},
+			isErrorExpected: true,
+			isKillExpected:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := testCase.config
+			config["inline"] = []string{"Write-Output 'Hello, world!';"}
+			p, communicator := testProvisioner(t, config, testCase.rules...)
+
+			scriptPaths, e := p.initializeScriptCollection()
+
+			if nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t))
+
+			if testCase.isErrorExpected != (nil != e) {
+				t.Fatalf("expected error: %t, got %v", testCase.isErrorExpected, e)
+			}
+
+			if kills := communicator.Commands("kill_tree"); testCase.isKillExpected != (1 == len(kills)) {
+				t.Errorf("expected kill: %t, got %q", testCase.isKillExpected, kills)
+			} else if testCase.isKillExpected && !strings.Contains(e.Error(), (scriptPaths[0]+"; timed out after 50ms")) {
+				t.Errorf("expected the timeout error to name %s, got %q", scriptPaths[0], e.Error())
+			}
+
+			if uploaded, ok := communicator.uploads[p.config.RemotePath]; !ok || !strings.Contains(uploaded, "Hello, world!") {
+				t.Errorf("expected the inline script to be uploaded to %s", p.config.RemotePath)
+			}
+
+			for name, value := range testCase.expectedOutputs {
+				if value != p.generatedData[name] {
+					t.Errorf("expected output %s to be %q, got %v", name, value, p.generatedData[name])
+				}
+			}
+
+			if _, e = os.Stat(scriptPaths[0]); !os.IsNotExist(e) {
+				t.Errorf("expected the temporary inline script to be removed")
+			}
+		})
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionKeepsUserScripts(t *testing.T) {
+	scriptPath := filepath.Join(t.TempDir(), "user.ps1")
+
+	if e := os.WriteFile(scriptPath, []byte("Write-Output 'Hello, world!';\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	p, communicator := testProvisioner(t, map[string]interface{}{"scripts": []string{scriptPath}})
+
+	if e := p.executeScriptCollection(context.Background(), []string{scriptPath}, packersdk.TestUi(t)); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if 1 != len(communicator.commands) {
+		t.Errorf("expected exactly one command, got %d", len(communicator.commands))
+	}
+
+	if _, e := os.Stat(scriptPath); nil != e {
+		t.Errorf("expected the user script to be kept: %s", e)
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionRemovesEnvVars(t *testing.T) {
+	p, communicator := testProvisioner(t, map[string]interface{}{
+		"env":     map[string]string{"GREETING": "hello"},
+		"inline":  []string{"Write-Output 'Hello, world!';"},
+		"os_type": "ubuntu",
+	})
+
+	scriptPaths, e := p.initializeScriptCollection()
+
+	if nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if _, ok := communicator.uploads[p.config.RemoteEnvVarPath]; !ok {
+		t.Errorf("expected the environment variable file to be uploaded to %s", p.config.RemoteEnvVarPath)
+	}
+
+	if removals := communicator.Commands("rm -f " + quoteLinuxArgument(p.config.RemoteEnvVarPath)); 1 != len(removals) {
+		t.Errorf("expected the environment variable file to be removed once, got %q", communicator.commands)
+	} else if !strings.HasPrefix(communicator.commands[len(communicator.commands)-1], "rm -f") {
+		t.Errorf("expected the environment variable file to be removed after the scripts, got %q", communicator.commands)
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionWithLogDir(t *testing.T) {
+	directory := t.TempDir()
+	logDir := filepath.Join(directory, "logs")
+	scriptPaths := []string{filepath.Join(directory, "first.ps1"), filepath.Join(directory, "second.ps1")}
+
+	for _, scriptPath := range scriptPaths {
+		if e := os.WriteFile(scriptPath, []byte("Write-Output '"+filepath.Base(scriptPath)+"';\n"), 0644); nil != e {
+			t.Fatalf("unexpected error: %s", e)
+		}
+	}
+
+	p, _ := testProvisioner(
+		t,
+		map[string]interface{}{"log_dir": logDir, "scripts": scriptPaths},
+		&fakeCommandRule{script: "first.ps1", responses: []fakeCommandResponse{{stdout: "Hello from first\n"}}},
+		&fakeCommandRule{script: "second.ps1", responses: []fakeCommandResponse{{stdout: "Hello from second\n"}}},
+	)
+
+	if e := p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	for index, name := range []string{"first", "second"} {
+		logPath := filepath.Join(logDir, fmt.Sprintf("%03d-%s.ps1.log", index, name))
+
+		if content, e := os.ReadFile(logPath); nil != e {
+			t.Errorf("expected a log file for %s: %s", name, e)
+		} else if !strings.Contains(string(content), ("Hello from " + name)) {
+			t.Errorf("expected the log file for %s to contain its output, got %q", name, string(content))
+		} else if strings.Contains(string(content), "Hello from "+[]string{"second", "first"}[index]) {
+			t.Errorf("expected the log file for %s to only contain its own output, got %q", name, string(content))
+		}
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionWithReboot(t *testing.T) {
+	testRebootPollInterval(t)
+
+	testCases := []struct {
+		name            string
+		pendingExitCode int
+		expectedReboots int
+	}{
+		{"pending", 1, 1},
+		{"not pending", 0, 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p, communicator := testProvisioner(
+				t,
+				map[string]interface{}{
+					"inline":            []string{"Write-Output 'Hello, world!';"},
+					"os_type":           "windows",
+					"reboot_is_enabled": true,
+				},
+				&fakeCommandRule{command: `packer reboot"`, responses: []fakeCommandResponse{{exitCode: 0}}},
+				&fakeCommandRule{command: `exit 0;`, responses: []fakeCommandResponse{{exitCode: 0}}},
+				&fakeCommandRule{script: "PendingFileRenameOperations", responses: []fakeCommandResponse{{exitCode: testCase.pendingExitCode}}},
+				&fakeCommandRule{command: "packer reboot test", responses: []fakeCommandResponse{{exitCode: 1}}},
+			)
+
+			scriptPaths, e := p.initializeScriptCollection()
+
+			if nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if actual := len(communicator.Commands(p.config.RebootInitiateCommand)); testCase.expectedReboots != actual {
+				t.Errorf("expected %d reboots, got %d", testCase.expectedReboots, actual)
+			}
+		})
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionWithSensitiveEnv(t *testing.T) {
+	sensitiveEnv := base64.StdEncoding.EncodeToString([]byte(`{"API_TOKEN":"token"}`))
+	testCases := []struct {
+		name            string
+		config          map[string]interface{}
+		expectedCommand string
+		expectedStdin   []string
+	}{
+		{
+			name:            "windows",
+			config:          map[string]interface{}{"os_type": "windows"},
+			expectedCommand: ("$sensitiveEnv = '" + sensitiveEnv + "';"),
+			expectedStdin:   []string{},
+		},
+		{
+			name:            "linux",
+			config:          map[string]interface{}{"os_type": "ubuntu"},
+			expectedCommand: "$sensitiveEnv = [Console]::In.ReadLine();",
+			expectedStdin:   []string{(sensitiveEnv + "\n")},
+		},
+		{
+			name: "sudo with password",
+			config: map[string]interface{}{
+				"elevated_password": "secret",
+				"elevated_user":     "packer",
+				"elevation_method":  "sudo",
+				"os_type":           "ubuntu",
+			},
+			expectedCommand: "$sensitiveEnv = [Console]::In.ReadLine();",
+			expectedStdin:   []string{("secret\n" + sensitiveEnv + "\n")},
+		},
+		{
+			name: "run as user",
+			config: map[string]interface{}{
+				"os_type":     "ubuntu",
+				"run_as_user": "builder",
+			},
+			expectedCommand: "$sensitiveEnv = [Console]::In.ReadLine();",
+			expectedStdin:   []string{(sensitiveEnv + "\n")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := testCase.config
+			config["inline"] = []string{"Write-Output 'Hello, world!';"}
+			config["sensitive_env"] = map[string]string{"API_TOKEN": "token"}
+			p, communicator := testProvisioner(t, config)
+
+			scriptPaths, e := p.initializeScriptCollection()
+
+			if nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if !strings.Contains(communicator.commands[0], testCase.expectedCommand) {
+				t.Errorf("expected the command to contain %q, got %q", testCase.expectedCommand, communicator.commands[0])
+			}
+
+			if strings.Join(testCase.expectedStdin, "|") != strings.Join(communicator.stdin, "|") {
+				t.Errorf("expected stdin %q, got %q", testCase.expectedStdin, communicator.stdin)
+			}
+
+			for remotePath, uploaded := range communicator.uploads {
+				if strings.Contains(uploaded, sensitiveEnv) || strings.Contains(uploaded, "token") {
+					t.Errorf("expected the sensitive environment to never be uploaded, got %q at %s", uploaded, remotePath)
+				}
+			}
+
+			if _, ok := p.generatedData["SensitiveEnv"]; ok {
+				t.Error("expected the sensitive environment to stay out of the build data")
+			}
+		})
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionWithTemplates(t *testing.T) {
+	directory := t.TempDir()
+	delimitedScriptPath := filepath.Join(directory, "delimited.ps1")
+	scriptPath := filepath.Join(directory, "user.ps1")
+
+	if e := os.WriteFile(delimitedScriptPath, []byte("$map = @{ Name = '[[ .Greeting ]]' }; if ($true) {{ }}\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.WriteFile(scriptPath, []byte("Write-Output '{{ .Greeting }}';\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "inline",
+			config:   map[string]interface{}{"inline": []string{"Write-Output '{{ .Greeting }}';"}, "template_scripts": true},
+			expected: "Write-Output 'Hello';\n",
+		},
+		{
+			name:     "script",
+			config:   map[string]interface{}{"scripts": []string{scriptPath}, "template_scripts": true},
+			expected: "Write-Output 'Hello';\n",
+		},
+		{
+			name:     "delimiters",
+			config:   map[string]interface{}{"scripts": []string{delimitedScriptPath}, "template_delimiters": []string{"[[", "]]"}, "template_scripts": true},
+			expected: "$map = @{ Name = 'Hello' }; if ($true) {{ }}\n",
+		},
+		{
+			name:     "disabled",
+			config:   map[string]interface{}{"scripts": []string{scriptPath}},
+			expected: "Write-Output '{{ .Greeting }}';\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		p, communicator := testProvisioner(t, testCase.config)
+		p.generatedData["Greeting"] = "Hello"
+
+		if scriptPaths, e := p.initializeScriptCollection(); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.name, e)
+		} else if e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); nil != e {
+			t.Fatalf("%s: unexpected error: %s", testCase.name, e)
+		}
+
+		if actual := communicator.uploads[p.config.RemotePath]; testCase.expected != actual {
+			t.Errorf("%s: expected %q to be uploaded, got %q", testCase.name, testCase.expected, actual)
+		}
+	}
+
+	if content, e := os.ReadFile(scriptPath); nil != e {
+		t.Errorf("expected the user script to be kept: %s", e)
+	} else if "Write-Output '{{ .Greeting }}';\n" != string(content) {
+		t.Errorf("expected the user script to be left unrendered, got %q", string(content))
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionWithTemplatesNamesUserScript(t *testing.T) {
+	scriptPath := filepath.Join(t.TempDir(), "user.ps1")
+
+	if e := os.WriteFile(scriptPath, []byte("Write-Output '{{ .Greeting }}';\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	testCases := []struct {
+		name   string
+		config map[string]interface{}
+		rules  []*fakeCommandRule
+	}{
+		{
+			name: "error record",
+			rules: []*fakeCommandRule{{responses: []fakeCommandResponse{{
+				exitCode: 1,
+				stderr:   `packer-error:{"Category":"NotSpecified: (:) [], RuntimeException","Line":1,"Message":"Boom","Position":"At line:1","ScriptName":"/tmp/packer-pwsh-user.ps1","ScriptStackTrace":"at <ScriptBlock>"}` + "\n",
+			}}}},
+		},
+		{
+			name:   "timeout",
+			config: map[string]interface{}{"script_timeout": "50ms"},
+			rules: []*fakeCommandRule{
+				{command: "pgrep", responses: []fakeCommandResponse{{}}},
+				{responses: []fakeCommandResponse{{delay: time.Minute}}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"remote_path":      "/tmp/packer-pwsh-user.ps1",
+				"scripts":          []string{scriptPath},
+				"template_scripts": true,
+			}
+
+			for key, value := range testCase.config {
+				config[key] = value
+			}
+
+			p, _ := testProvisioner(t, config, testCase.rules...)
+			p.generatedData["Greeting"] = "Hello"
+
+			if scriptPaths, e := p.initializeScriptCollection(); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			} else if e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); nil == e {
+				t.Fatal("expected the script to fail")
+			} else if !strings.Contains(e.Error(), scriptPath) || strings.Contains(e.Error(), "pwsh-template") {
+				t.Errorf("expected the error to name %s rather than the rendered copy, got %q", scriptPath, e.Error())
+			}
+		})
+	}
+}
+func TestProvisioner_ExecuteScriptCollectionWithWorkingDirectory(t *testing.T) {
+	directory := t.TempDir()
+	overriddenScriptPath := filepath.Join(directory, "overridden.ps1")
+	scriptPath := filepath.Join(directory, "global.ps1")
+
+	for _, path := range []string{overriddenScriptPath, scriptPath} {
+		if e := os.WriteFile(path, []byte("Get-Location;\n"), 0644); nil != e {
+			t.Fatalf("unexpected error: %s", e)
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		executeCommand   string
+		expectedCommands []string
+	}{
+		{
+			name: "default",
+			expectedCommands: []string{
+				"$workingDirectory = '/srv/override'; if ($workingDirectory) { New-Item -Force -ItemType Directory -Path $workingDirectory | Out-Null; Set-Location -Path $workingDirectory; }",
+				"$workingDirectory = '/srv/global'; if ($workingDirectory) { New-Item -Force -ItemType Directory -Path $workingDirectory | Out-Null; Set-Location -Path $workingDirectory; }",
+			},
+		},
+		{
+			name:             "custom",
+			executeCommand:   "cd {{.WorkingDirectory}} && pwsh -File {{.Path}}",
+			expectedCommands: []string{"cd /srv/override && pwsh -File", "cd /srv/global && pwsh -File"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p, communicator := testProvisioner(t, map[string]interface{}{
+				"execute_command":   testCase.executeCommand,
+				"os_type":           "windows",
+				"script_override":   []map[string]interface{}{{"path": overriddenScriptPath, "working_directory": "/srv/override"}},
+				"scripts":           []string{overriddenScriptPath, scriptPath},
+				"working_directory": "/srv/global",
+			})
+
+			if e := p.executeScriptCollection(context.Background(), p.config.Scripts, packersdk.TestUi(t)); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if len(testCase.expectedCommands) != len(communicator.commands) {
+				t.Fatalf("expected %d commands, got %q", len(testCase.expectedCommands), communicator.commands)
+			}
+
+			for index, expectedCommand := range testCase.expectedCommands {
+				if !strings.Contains(communicator.commands[index], expectedCommand) {
+					t.Errorf("expected command %d to contain %q, got %q", index, expectedCommand, communicator.commands[index])
+				}
+			}
+
+			if "/srv/global" != p.generatedData["WorkingDirectory"] {
+				t.Errorf("expected the global working directory to be restored, got %v", p.generatedData["WorkingDirectory"])
+			}
+		})
+	}
+}
+func TestProvisioner_GetEnvVarScript(t *testing.T) {
+	p := new(Provisioner)
+
+	if e := p.Prepare(map[string]interface{}{
+		"env":                 map[string]string{"B_VAR": "it's", "A_VAR": "a=b"},
+		"environment_vars":    []string{"GREETING=hello world"},
+		"inline":              []string{"Write-Output 'Hello, world!';"},
+		"packer_build_name":   "ubuntu",
+		"packer_builder_type": "null",
+	}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	expected := strings.Join([]string{
+		"[Environment]::SetEnvironmentVariable('PACKER_BUILD_NAME', 'ubuntu');",
+		"[Environment]::SetEnvironmentVariable('PACKER_BUILDER_TYPE', 'null');",
+		"[Environment]::SetEnvironmentVariable('GREETING', 'hello world');",
+		"[Environment]::SetEnvironmentVariable('A_VAR', 'a=b');",
+		"[Environment]::SetEnvironmentVariable('B_VAR', 'it''s');",
+		"",
+	}, "\n")
+
+	if actual := p.getEnvVarScript(); expected != actual {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if !strings.Contains(p.config.ExecuteCommand, `. '{{.Vars}}';`) {
+		t.Errorf("expected the execute command to dot-source the environment variable script: %q", p.config.ExecuteCommand)
+	}
+
+	if e := new(Provisioner).Prepare(map[string]interface{}{
+		"environment_vars": []string{"1INVALID=value"},
+		"inline":           []string{"Write-Output 'Hello, world!';"},
+	}); nil == e {
+		t.Error("expected an error for an invalid environment variable name")
+	}
+}
+func TestProvisioner_KillScript(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         map[string]interface{}
+		expectedPrefix string
+		expectedStdin  []string
+	}{
+		{
+			name:           "connecting user",
+			config:         map[string]interface{}{},
+			expectedPrefix: "kill_tree() {",
+			expectedStdin:  []string{},
+		},
+		{
+			name:           "elevated",
+			config:         map[string]interface{}{"elevated_password": "secret", "elevated_user": "packer"},
+			expectedPrefix: "sudo -S -p '' sh -e -c 'kill_tree() {",
+			expectedStdin:  []string{"secret\n"},
+		},
+		{
+			name:           "run as",
+			config:         map[string]interface{}{"run_as_user": "svc-app"},
+			expectedPrefix: "chmod a+rx /tmp/script.ps1 && sudo -n -u svc-app sh -e -c 'export HOME=",
+			expectedStdin:  []string{},
+		},
+		{
+			name:           "windows elevated",
+			config:         map[string]interface{}{"elevated_password": "secret", "elevated_user": "packer", "os_type": "windows"},
+			expectedPrefix: "powershell -ExecutionPolicy Bypass -NoLogo -NonInteractive -NoProfile -File",
+			expectedStdin:  []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := testCase.config
+			config["inline"] = []string{"Write-Output 'Hello, world!';"}
+
+			if _, ok := config["os_type"]; !ok {
+				config["os_type"] = "ubuntu"
+			}
+
+			p, communicator := testProvisioner(t, config)
+			p.generatedData["Path"] = "/tmp/script.ps1"
+
+			if e := p.killScript(context.Background(), packersdk.TestUi(t)); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if !strings.HasPrefix(communicator.commands[0], testCase.expectedPrefix) {
+				t.Errorf("expected prefix %q, got %q", testCase.expectedPrefix, communicator.commands[0])
+			}
+
+			if strings.Join(testCase.expectedStdin, "|") != strings.Join(communicator.stdin, "|") {
+				t.Errorf("expected stdin %q, got %q", testCase.expectedStdin, communicator.stdin)
+			}
+		})
+	}
+}
+func TestProvisioner_KillScriptProcessTree(t *testing.T) {
+	if "linux" != runtime.GOOS {
+		t.Skip("the default kill command targets Linux")
+	} else if _, e := exec.LookPath("pgrep"); nil != e {
+		t.Skip("pgrep is not available")
+	}
+
+	// A copy of sh named pwsh stands in for the script; it starts a child and a grandchild, like an installer launched from a shell.
+	directory := t.TempDir()
+	pwshPath := filepath.Join(directory, "pwsh")
+	scriptPath := filepath.Join(directory, "script.ps1")
+
+	if shellPath, e := exec.LookPath("sh"); nil != e {
+		t.Skip("sh is not available")
+	} else if content, e := os.ReadFile(shellPath); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if e = os.WriteFile(pwshPath, content, 0755); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	scriptCmd := exec.Command(pwshPath, "-c", "sleep 3013 & sh -c 'sleep 3017 & wait' & wait", scriptPath)
+
+	if e := scriptCmd.Start(); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	defer scriptCmd.Process.Kill()
+
+	isRunning := func(pattern string) bool {
+		return nil == exec.Command("pgrep", "-f", pattern).Run()
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); !isRunning("^sleep 3017$"); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the grandchild process to start")
+		}
+	}
+
+	p, _ := testProvisioner(t, map[string]interface{}{
+		"inline":  []string{"Write-Output 'Hello, world!';"},
+		"os_type": "ubuntu",
+	})
+	p.generatedData["Path"] = scriptPath
+
+	if command, e := interpolate.Render(p.config.ScriptKillCommand, &p.config.ctx); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if output, e := exec.Command("sh", "-e", "-c", command).CombinedOutput(); nil != e {
+		t.Fatalf("unexpected error: %s (%s)", e, output)
+	}
+
+	scriptCmd.Wait()
+
+	for deadline := time.Now().Add(5 * time.Second); isRunning("^sleep 301[37]$"); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected every descendant of the script to be killed")
+		}
+	}
+}
+func TestProvisioner_PrepareDefaults(t *testing.T) {
+	testCases := []struct {
+		osType                         string
+		expectedElevatedExecuteCommand string
+		expectedExecuteCommandPrefix   string
+		expectedPwshPath               string
+		expectedRemotePathPrefix       string
+		isPwshAutoUpdateExpected       bool
+		isRebootPendingExpected        bool
+	}{
+		{"", `sudo -n sh -e -c %s`, `chmod +x {{.Path}} && '{{.PwshPath}}' -ExecutionPolicy Bypass`, "pwsh", "/tmp/packer-pwsh-script-", false, false},
+		{"debian", `sudo -n sh -e -c %s`, `chmod +x {{.Path}} && '{{.PwshPath}}' -ExecutionPolicy Bypass`, "pwsh", "/tmp/packer-pwsh-script-", true, false},
+		{"ubuntu", `sudo -n sh -e -c %s`, `chmod +x {{.Path}} && '{{.PwshPath}}' -ExecutionPolicy Bypass`, "pwsh", "/tmp/packer-pwsh-script-", true, false},
+		{"Windows", `%s`, `"{{.PwshPath}}" -ExecutionPolicy Bypass`, "", "C:/Windows/Temp/packer-pwsh-script-", true, true},
+	}
+
+	for _, testCase := range testCases {
+		p := new(Provisioner)
+
+		if e := p.Prepare(map[string]interface{}{
+			"inline":  []string{"Write-Output 'Hello, world!';"},
+			"os_type": testCase.osType,
+		}); nil != e {
+			t.Fatalf("%q: unexpected error: %s", testCase.osType, e)
+		}
+
+		if testCase.expectedElevatedExecuteCommand != p.config.ElevatedExecuteCommand {
+			t.Errorf("%q: expected elevated execute command %q, got %q", testCase.osType, testCase.expectedElevatedExecuteCommand, p.config.ElevatedExecuteCommand)
+		}
+
+		if !strings.HasPrefix(p.config.ExecuteCommand, testCase.expectedExecuteCommandPrefix) {
+			t.Errorf("%q: expected execute command prefix %q, got %q", testCase.osType, testCase.expectedExecuteCommandPrefix, p.config.ExecuteCommand)
+		}
+
+		if testCase.expectedPwshPath != p.config.PwshPath {
+			t.Errorf("%q: expected pwsh path %q, got %q", testCase.osType, testCase.expectedPwshPath, p.config.PwshPath)
+		}
+
+		if !strings.HasPrefix(p.config.RemotePath, testCase.expectedRemotePathPrefix) {
+			t.Errorf("%q: expected remote path prefix %q, got %q", testCase.osType, testCase.expectedRemotePathPrefix, p.config.RemotePath)
+		}
+
+		if testCase.isPwshAutoUpdateExpected != ("" != p.config.PwshAutoUpdateCommand) {
+			t.Errorf("%q: expected auto update command: %t", testCase.osType, testCase.isPwshAutoUpdateExpected)
+		}
+
+		if testCase.isRebootPendingExpected != ("" != p.config.RebootPendingCommand) {
+			t.Errorf("%q: expected reboot pending command: %t", testCase.osType, testCase.isRebootPendingExpected)
+		}
+
+		if ("Stop" != p.config.ErrorActionPreference) || ("Bypass" != p.config.ExecutionPolicy) || ("SilentlyContinue" != p.config.ProgressPreference) {
+			t.Errorf("%q: unexpected preference defaults", testCase.osType)
+		}
+	}
+}
+func TestProvisioner_PreparePowershellEdition(t *testing.T) {
+	testCases := []struct {
+		edition         string
+		executeCommand  string
+		osType          string
+		isErrorExpected bool
+	}{
+		{"auto", "/opt/custom/bin/run-script {{.Path}}", "ubuntu", false},
+		{"core", "", "ubuntu", false},
+		{"core", "{{.PwshPath}} -File {{.Path}}", "ubuntu", false},
+		{"core", "/opt/custom/bin/run-script {{.Path}}", "ubuntu", true},
+		{"desktop", "", "windows", false},
+		{"desktop", "powershell -File {{.Path}}", "windows", true},
+		{"desktop", "", "ubuntu", true},
+		{"legacy", "", "ubuntu", true},
+	}
+
+	for _, testCase := range testCases {
+		if e := new(Provisioner).Prepare(map[string]interface{}{
+			"execute_command":    testCase.executeCommand,
+			"inline":             []string{"Write-Output 'Hello, world!';"},
+			"os_type":            testCase.osType,
+			"powershell_edition": testCase.edition,
+		}); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%s (%q): expected error: %t, got %v", testCase.edition, testCase.executeCommand, testCase.isErrorExpected, e)
+		}
+	}
+}
+func TestProvisioner_PrepareRedactsSecrets(t *testing.T) {
+	manifestPath, _ := testSha256Manifest(t, "Write-Output 'Hello, world!';\n")
+	scriptPath := filepath.Join(t.TempDir(), "token-value-123.ps1")
+
+	if e := os.WriteFile(scriptPath, []byte("Write-Output 'Hello, world!';\n"), 0644); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	e := new(Provisioner).Prepare(map[string]interface{}{
+		"script_sha256_manifest": manifestPath,
+		"scripts":                []string{scriptPath},
+		"sensitive_env":          map[string]string{"API_TOKEN": "token-value-123"},
+	})
+
+	if nil == e {
+		t.Fatal("expected the unlisted script to be rejected")
+	} else if !strings.Contains(e.Error(), "<sensitive>.ps1 is not listed in the manifest") || strings.Contains(e.Error(), "token-value-123") {
+		t.Errorf("expected the manifest error to be redacted, got %q", e.Error())
+	}
+}
+func TestProvisioner_PrepareTemplateScripts(t *testing.T) {
+	testCases := []struct {
+		config          map[string]interface{}
+		isErrorExpected bool
+	}{
+		{map[string]interface{}{"template_scripts": true}, false},
+		{map[string]interface{}{"template_delimiters": []string{"[[", "]]"}, "template_scripts": true}, false},
+		{map[string]interface{}{"template_delimiters": []string{"[["}, "template_scripts": true}, true},
+		{map[string]interface{}{"template_delimiters": []string{"[[", ""}, "template_scripts": true}, true},
+		{map[string]interface{}{"template_delimiters": []string{"[[", "]]"}}, true},
+		{map[string]interface{}{"execution_policy": "AllSigned", "scripts": []string{"provisioner.go"}, "template_scripts": true}, true},
+		{map[string]interface{}{"inline": []string{"Write-Output '[[ .Greeting ';"}, "template_delimiters": []string{"[[", "]]"}, "template_scripts": true}, true},
+	}
+
+	for _, testCase := range testCases {
+		_, hasInline := testCase.config["inline"]
+		_, hasScripts := testCase.config["scripts"]
+
+		if !hasInline && !hasScripts {
+			testCase.config["inline"] = []string{"Write-Output '{{ .Greeting }}';"}
+		}
+
+		if e := new(Provisioner).Prepare(testCase.config); testCase.isErrorExpected != (nil != e) {
+			t.Errorf("%v: expected error: %t, got %v", testCase.config, testCase.isErrorExpected, e)
+		}
+	}
+}
+func TestProvisioner_ProvisionRedactsArtifacts(t *testing.T) {
+	destination := (t.TempDir() + string(os.PathSeparator))
+	transcriptDir := t.TempDir()
+	p := new(Provisioner)
+
+	if e := p.Prepare(map[string]interface{}{
+		"download":       []map[string]interface{}{{"destination": destination, "source": "/tmp/token-value-123.log"}},
+		"inline":         []string{"Write-Output 'Hello, world!';"},
+		"os_type":        "ubuntu",
+		"sensitive_env":  map[string]string{"API_TOKEN": "token-value-123"},
+		"transcript_dir": transcriptDir,
+	}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	communicator := newFakeCommunicator(
+		&fakeCommandRule{command: "base64 -d | sh", responses: []fakeCommandResponse{{stdout: (pwshResolvePrefix + "/usr/bin/pwsh|Core|7.4.0\n")}}},
+		&fakeCommandRule{script: "Get-Item", responses: []fakeCommandResponse{{stdout: (pwshDownloadPrefix + "F:/tmp/token-value-123.log\n")}}},
+	)
+	communicator.files["/tmp/token-value-123.log"] = "Hello, world!\n"
+	communicator.files[p.config.RemoteTranscriptPath] = "PS> Connect -Token token-value-123\n"
+	var errorOutput bytes.Buffer
+	var output bytes.Buffer
+
+	ui := &packersdk.BasicUi{ErrorWriter: &errorOutput, Reader: new(bytes.Buffer), Writer: &output}
+
+	if e := p.Provision(context.Background(), ui, communicator, make(map[string]interface{})); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if !strings.Contains(output.String(), "remote path: /tmp/<sensitive>.log") {
+		t.Errorf("expected the download to be reported with a placeholder, got %q", output.String())
+	} else if strings.Contains((errorOutput.String() + output.String()), "token-value-123") {
+		t.Errorf("expected the output to be redacted, got %q", (errorOutput.String() + output.String()))
+	}
+
+	if transcriptPaths, e := filepath.Glob(filepath.Join(transcriptDir, "*.transcript.txt")); (nil != e) || (1 != len(transcriptPaths)) {
+		t.Fatalf("expected exactly one transcript, got %v (%v)", transcriptPaths, e)
+	} else if content, e := os.ReadFile(transcriptPaths[0]); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	} else if "PS> Connect -Token <sensitive>\n" != string(content) {
+		t.Errorf("expected the transcript to be redacted, got %q", string(content))
+	}
+}
+func TestProvisioner_ProvisionRedactsSecrets(t *testing.T) {
+	logDir := t.TempDir()
+	p := new(Provisioner)
+
+	if e := p.Prepare(map[string]interface{}{
+		"elevated_password": "hunter2-password",
+		"elevated_user":     "packer",
+		"inline":            []string{"Write-Output 'Hello, world!';"},
+		"log_dir":           logDir,
+		"os_type":           "ubuntu",
+		"sensitive_env":     map[string]string{"API_TOKEN": "token-value-123"},
+	}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	communicator := newFakeCommunicator(
+		&fakeCommandRule{command: "base64 -d | sh", responses: []fakeCommandResponse{{stdout: (pwshResolvePrefix + "/usr/bin/pwsh|Core|7.4.0\n")}}},
+		&fakeCommandRule{command: "packer-pwsh-script", responses: []fakeCommandResponse{{
+			exitCode: 1,
+			stderr:   `packer-error:{"Category":"NotSpecified","Line":1,"Message":"Rejected hunter2-password and token-value-123","Position":"At line:1","ScriptName":"","ScriptStackTrace":"at <ScriptBlock>"}` + "\n",
+			stdout:   "Using hunter2-password and token-value-123\n",
+		}}},
+	)
+	var errorOutput bytes.Buffer
+	var output bytes.Buffer
+
+	ui := &packersdk.BasicUi{ErrorWriter: &errorOutput, Reader: new(bytes.Buffer), Writer: &output}
+	e := p.Provision(context.Background(), ui, communicator, make(map[string]interface{}))
+
+	if nil == e {
+		t.Fatal("expected the error record to fail the script")
+	}
+
+	logContent := ""
+
+	if logPaths, err := filepath.Glob(filepath.Join(logDir, "*.log")); nil != err || (1 != len(logPaths)) {
+		t.Fatalf("expected exactly one log file, got %v (%v)", logPaths, err)
+	} else if content, err := os.ReadFile(logPaths[0]); nil != err {
+		t.Fatalf("unexpected error: %s", err)
+	} else {
+		logContent = string(content)
+	}
+
+	for source, text := range map[string]string{
+		"error":    e.Error(),
+		"log file": logContent,
+		"stderr":   errorOutput.String(),
+		"stdout":   output.String(),
+	} {
+		if strings.Contains(text, "hunter2-password") || strings.Contains(text, "token-value-123") {
+			t.Errorf("expected the %s to be redacted, got %q", source, text)
+		}
+	}
+
+	if !strings.Contains(output.String(), "Using <sensitive> and <sensitive>") {
+		t.Errorf("expected the script output to be shown with placeholders, got %q", output.String())
+	}
+
+	if !strings.Contains(logContent, "Using <sensitive> and <sensitive>") {
+		t.Errorf("expected the log file to contain the redacted output, got %q", logContent)
+	}
+
+	if !strings.Contains(e.Error(), "Rejected <sensitive> and <sensitive>") {
+		t.Errorf("expected the error to carry the redacted message, got %q", e.Error())
+	}
+}
+func TestProvisioner_ProvisionWithExecuteCommand(t *testing.T) {
+	testCases := []struct {
+		executeCommand    string
+		isResolveExpected bool
+	}{
+		{"", true},
+		{"{{.PwshPath}} -File {{.Path}}", true},
+		{"/opt/custom/bin/run-script {{.Path}}", false},
+	}
+
+	for _, testCase := range testCases {
+		p, communicator := testProvisioner(
+			t,
+			map[string]interface{}{
+				"execute_command": testCase.executeCommand,
+				"inline":          []string{"Write-Output 'Hello, world!';"},
+				"os_type":         "ubuntu",
+			},
+			&fakeCommandRule{command: "base64 -d | sh", responses: []fakeCommandResponse{{stdout: (pwshResolvePrefix + "/usr/bin/pwsh|Core|7.4.0\n")}}},
+		)
+
+		if e := p.provision(context.Background(), packersdk.TestUi(t)); nil != e {
+			t.Fatalf("%q: unexpected error: %s", testCase.executeCommand, e)
+		}
+
+		if isResolved := (0 < len(communicator.Commands("base64 -d | sh"))); testCase.isResolveExpected != isResolved {
+			t.Errorf("%q: expected resolution: %t, got %t", testCase.executeCommand, testCase.isResolveExpected, isResolved)
+		}
+	}
+}
+func TestProvisioner_ProvisionWithOutputFile(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "build", "pwsh-outputs.json")
+	p := new(Provisioner)
+
+	if e := p.Prepare(map[string]interface{}{
+		"inline":      []string{"Write-Output 'packer-output:AppVersion=1.2.3';"},
+		"os_type":     "ubuntu",
+		"output_file": outputFile,
+	}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	communicator := newFakeCommunicator(
+		&fakeCommandRule{command: "base64 -d | sh", responses: []fakeCommandResponse{{stdout: (pwshResolvePrefix + "/usr/bin/pwsh|Core|7.4.0\n")}}},
+		&fakeCommandRule{responses: []fakeCommandResponse{{stdout: "packer-output:AppVersion=1.2.3\n"}}},
+	)
+
+	if e := p.Provision(context.Background(), packersdk.TestUi(t), communicator, map[string]interface{}{"PackerRunUUID": "run"}); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	var outputs map[string]string
+
+	if content, e := os.ReadFile(outputFile); nil != e {
+		t.Fatalf("expected the output file to be written: %s", e)
+	} else if e = json.Unmarshal(content, &outputs); nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	expected := map[string]string{
+		"PwshEdition":           "Core",
+		"PwshOutput_AppVersion": "1.2.3",
+		"PwshPath":              "/usr/bin/pwsh",
+		"PwshVersion":           "7.4.0",
+	}
+
+	if len(expected) != len(outputs) {
+		t.Errorf("expected outputs %v, got %v", expected, outputs)
+	}
+
+	for name, value := range expected {
+		if value != outputs[name] {
+			t.Errorf("expected output %s to be %q, got %q", name, value, outputs[name])
+		}
+	}
+}
+func TestProvisioner_RebootMachine(t *testing.T) {
+	testRebootPollInterval(t)
+
+	testCases := []struct {
+		name                     string
+		rules                    []*fakeCommandRule
+		isErrorExpected          bool
+		expectedCompleteCommands int
+		expectedValidateCommands int
+	}{
+		{
+			name:                     "initiate failure",
+			rules:                    []*fakeCommandRule{{command: "packer reboot\"", responses: []fakeCommandResponse{{exitCode: 5}}}},
+			isErrorExpected:          true,
+			expectedValidateCommands: 0,
+		},
+		{
+			name: "reboot in progress",
+			rules: []*fakeCommandRule{
+				{command: "packer reboot test", responses: []fakeCommandResponse{{exitCode: 1115}, {exitCode: 1}}},
+			},
+			expectedValidateCommands: 1,
+		},
+		{
+			name: "reboot not yet started",
+			rules: []*fakeCommandRule{
+				{command: "packer reboot test", responses: []fakeCommandResponse{{exitCode: 0}}},
+			},
+			expectedCompleteCommands: 1,
+			expectedValidateCommands: 1,
+		},
+		{
+			name: "disconnect while validating",
+			rules: []*fakeCommandRule{
+				{command: "packer reboot test", responses: []fakeCommandResponse{{disconnect: true}}},
+				{command: "exit 0;", responses: []fakeCommandResponse{{disconnect: true}, {exitCode: 1}, {exitCode: 0}}},
+			},
+			expectedValidateCommands: 3,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p, communicator := testProvisioner(
+				t,
+				map[string]interface{}{
+					"inline":                  []string{"Write-Output 'Hello, world!';"},
+					"os_type":                 "windows",
+					"reboot_validate_command": `pwsh -Command "exit 0;"`,
+				},
+				testCase.rules...,
+			)
+
+			if e := p.rebootMachine(context.Background(), packersdk.TestUi(t)); testCase.isErrorExpected != (nil != e) {
+				t.Fatalf("expected error: %t, got %v", testCase.isErrorExpected, e)
+			}
+
+			if actual := len(communicator.Commands(p.config.RebootCompleteCommand)); testCase.expectedCompleteCommands != actual {
+				t.Errorf("expected %d complete commands, got %d", testCase.expectedCompleteCommands, actual)
+			}
+
+			if actual := len(communicator.Commands(p.config.RebootValidateCommand)); testCase.expectedValidateCommands != actual {
+				t.Errorf("expected %d validate commands, got %d", testCase.expectedValidateCommands, actual)
+			}
+		})
+	}
+}
+func TestProvisioner_UpdatePwshInstallation(t *testing.T) {
+	testCases := []struct {
+		osType          string
+		exitCode        int
+		expectedCommand string
+		isErrorExpected bool
+	}{
+		{"ubuntu", 0, "chmod +x /tmp/packer-pwsh-installer-", false},
+		{"ubuntu", 1, "chmod +x /tmp/packer-pwsh-installer-", true},
+		{"windows", 0, `"powershell" -ExecutionPolicy Bypass`, false},
+	}
+
+	for _, testCase := range testCases {
+		p, communicator := testProvisioner(
+			t,
+			map[string]interface{}{
+				"inline":                     []string{"Write-Output 'Hello, world!';"},
+				"os_type":                    testCase.osType,
+				"pwsh_autoupdate_is_enabled": true,
+			},
+			&fakeCommandRule{responses: []fakeCommandResponse{{exitCode: testCase.exitCode}}},
+		)
+		originalExecuteCommand := p.config.ExecuteCommand
+
+		if e := p.updatePwshInstallation(context.Background(), packersdk.TestUi(t)); testCase.isErrorExpected != (nil != e) {
+			t.Fatalf("%s: expected error: %t, got %v", testCase.osType, testCase.isErrorExpected, e)
+		}
+
+		if uploaded, ok := communicator.uploads[p.config.RemotePwshAutoUpdatePath]; !ok || (p.config.PwshAutoUpdateCommand+"\n" != uploaded) {
+			t.Errorf("%s: expected the auto update script to be uploaded to %s", testCase.osType, p.config.RemotePwshAutoUpdatePath)
+		}
+
+		if 1 != len(communicator.Commands(testCase.expectedCommand)) {
+			t.Errorf("%s: expected one command containing %q, got %q", testCase.osType, testCase.expectedCommand, communicator.commands)
+		}
+
+		if originalExecuteCommand != p.config.ExecuteCommand {
+			t.Errorf("%s: expected the execute command to be restored", testCase.osType)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package pwsh
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+)
+
+func (p *Provisioner) renderScriptTemplate(scriptPath string) (string, error) {
+	if content, e := os.ReadFile(scriptPath); nil != e {
+		return "", fmt.Errorf(pwshScriptOpeningErrorFormat, e)
+	} else if rendered, e := p.renderTemplate(string(content)); nil != e {
+		return "", fmt.Errorf(pwshScriptTemplatingErrorFormat, scriptPath, e)
+	} else if directory, e := tmp.Dir("pwsh-template"); nil != e {
+		return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	} else {
+		// The rendered copy keeps the original file name so that remote paths derived from it are unchanged.
+		renderedScriptPath := filepath.Join(directory, filepath.Base(scriptPath))
+
+		if e = os.WriteFile(renderedScriptPath, []byte(rendered), 0644); nil != e {
+			os.RemoveAll(directory)
+
+			return "", fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+		}
+
+		return renderedScriptPath, nil
+	}
+}
+func (p *Provisioner) parseTemplate(content string) (*template.Template, error) {
+	return template.New("Script").Delims(p.config.TemplateDelimiters[0], p.config.TemplateDelimiters[1]).Funcs(interpolate.Funcs(&p.config.ctx)).Parse(content)
+}
+func (p *Provisioner) renderTemplate(content string) (string, error) {
+	// Inline lines are not rendered when the configuration is decoded; Prepare only checks that they parse with the configured delimiters.
+	if ("{{" == p.config.TemplateDelimiters[0]) && ("}}" == p.config.TemplateDelimiters[1]) {
+		return interpolate.Render(content, &p.config.ctx)
+	}
+
+	var buffer bytes.Buffer
+
+	if scriptTemplate, e := p.parseTemplate(content); nil != e {
+		return "", e
+	} else if e = scriptTemplate.Execute(&buffer, p.config.ctx.Data); nil != e {
+		return "", e
+	}
+
+	return buffer.String(), nil
+}
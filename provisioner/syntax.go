@@ -0,0 +1,272 @@
+package pwsh
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type syntaxFrame struct {
+	column int
+	kind   string
+	line   int
+}
+type syntaxScanner struct {
+	column int
+	index  int
+	line   int
+	name   string
+	runes  []rune
+	stack  []syntaxFrame
+}
+
+// checkPwshSyntax is a deliberately shallow check; it only tracks the nesting of brackets, comments and string
+// literals, which covers the mistakes that would otherwise surface once the machine has booted.
+func checkPwshSyntax(name string, content string) error {
+	s := &syntaxScanner{
+		column: 1,
+		line:   1,
+		name:   name,
+		runes:  []rune(strings.TrimPrefix(content, "\uFEFF")),
+		stack:  make([]syntaxFrame, 0),
+	}
+
+	for s.index < len(s.runes) {
+		var e error
+
+		switch s.top() {
+		case `"`:
+			s.scanExpandableString()
+		case `@"`:
+			s.scanExpandableHereString()
+		default:
+			e = s.scanCode()
+		}
+
+		if nil != e {
+			return e
+		}
+	}
+
+	if 0 < len(s.stack) {
+		frame := s.stack[len(s.stack)-1]
+
+		switch frame.kind {
+		case `"`:
+			return s.errorAt(frame.line, frame.column, `The string is missing the terminator: ".`)
+		case `@"`:
+			return s.errorAt(frame.line, frame.column, `The string is missing the terminator: "@.`)
+		default:
+			return s.errorAt(frame.line, frame.column, "Missing closing '%s'.", getSyntaxCloser(frame.kind))
+		}
+	}
+
+	return nil
+}
+func getSyntaxCloser(opener string) string {
+	switch opener {
+	case "(":
+		return ")"
+	case "[":
+		return "]"
+	default:
+		return "}"
+	}
+}
+
+func (p *Provisioner) checkScriptSyntax() error {
+	var e error
+
+	if nil != p.config.Inline {
+		if err := checkPwshSyntax("inline", (strings.Join(p.config.Inline, "\n") + "\n")); nil != err {
+			e = packersdk.MultiErrorAppend(e, err)
+		}
+	}
+
+	for _, scriptPath := range p.config.Scripts {
+		if content, err := os.ReadFile(scriptPath); nil != err {
+			e = packersdk.MultiErrorAppend(e, fmt.Errorf(pwshLintingErrorFormat, err))
+		} else if err = checkPwshSyntax(scriptPath, string(content)); nil != err {
+			e = packersdk.MultiErrorAppend(e, err)
+		}
+	}
+
+	return e
+}
+
+func (s *syntaxScanner) advance(count int) {
+	for ; (0 < count) && (s.index < len(s.runes)); count-- {
+		if '\n' == s.runes[s.index] {
+			s.column = 1
+			s.line++
+		} else {
+			s.column++
+		}
+
+		s.index++
+	}
+}
+func (s *syntaxScanner) errorAt(line int, column int, format string, arguments ...interface{}) error {
+	return fmt.Errorf("%s:%d:%d: %s", s.name, line, column, fmt.Sprintf(format, arguments...))
+}
+func (s *syntaxScanner) isLineStart() bool {
+	return (0 == s.index) || ('\n' == s.runes[s.index-1])
+}
+func (s *syntaxScanner) isTokenStart() bool {
+	return (0 == s.index) || strings.ContainsRune(" \t\r\n;|&(){}=,", s.runes[s.index-1])
+}
+func (s *syntaxScanner) peek(offset int) rune {
+	if (s.index + offset) < len(s.runes) {
+		return s.runes[s.index+offset]
+	}
+
+	return 0
+}
+func (s *syntaxScanner) pop() {
+	s.stack = s.stack[:len(s.stack)-1]
+}
+func (s *syntaxScanner) push(kind string) {
+	s.stack = append(s.stack, syntaxFrame{column: s.column, kind: kind, line: s.line})
+}
+func (s *syntaxScanner) scanBlockComment() error {
+	column, line := s.column, s.line
+
+	for s.advance(2); s.index < len(s.runes); s.advance(1) {
+		if ('#' == s.peek(0)) && ('>' == s.peek(1)) {
+			s.advance(2)
+
+			return nil
+		}
+	}
+
+	return s.errorAt(line, column, "The block comment is missing the terminator: #>.")
+}
+func (s *syntaxScanner) scanCode() error {
+	switch current := s.peek(0); {
+	case '`' == current:
+		s.advance(2)
+	case ('<' == current) && ('#' == s.peek(1)):
+		return s.scanBlockComment()
+	case ('#' == current) && s.isTokenStart():
+		for (s.index < len(s.runes)) && ('\n' != s.peek(0)) {
+			s.advance(1)
+		}
+	case ('@' == current) && (('"' == s.peek(1)) || ('\'' == s.peek(1))):
+		return s.scanHereStringHeader()
+	case '"' == current:
+		s.push(`"`)
+		s.advance(1)
+	case '\'' == current:
+		return s.scanVerbatimString()
+	case ('$' == current) && ('{' == s.peek(1)):
+		column, line := s.column, s.line
+
+		for s.advance(2); '}' != s.peek(0); s.advance(1) {
+			if s.index >= len(s.runes) {
+				return s.errorAt(line, column, "Missing closing '}' in variable name.")
+			}
+		}
+
+		s.advance(1)
+	case strings.ContainsRune("([{", current):
+		s.push(string(current))
+		s.advance(1)
+	case strings.ContainsRune(")]}", current):
+		if top := s.top(); ("" == top) || (getSyntaxCloser(top) != string(current)) {
+			return s.errorAt(s.line, s.column, "Unexpected token '%c'.", current)
+		}
+
+		s.pop()
+		s.advance(1)
+	default:
+		s.advance(1)
+	}
+
+	return nil
+}
+func (s *syntaxScanner) scanExpandableHereString() {
+	switch current := s.peek(0); {
+	case s.isLineStart() && ('"' == current) && ('@' == s.peek(1)):
+		s.pop()
+		s.advance(2)
+	case '`' == current:
+		s.advance(2)
+	case ('$' == current) && ('(' == s.peek(1)):
+		s.advance(1)
+		s.push("(")
+		s.advance(1)
+	default:
+		s.advance(1)
+	}
+}
+func (s *syntaxScanner) scanExpandableString() {
+	switch current := s.peek(0); {
+	case '`' == current:
+		s.advance(2)
+	case ('"' == current) && ('"' == s.peek(1)):
+		s.advance(2)
+	case '"' == current:
+		s.pop()
+		s.advance(1)
+	case ('$' == current) && ('(' == s.peek(1)):
+		s.advance(1)
+		s.push("(")
+		s.advance(1)
+	default:
+		s.advance(1)
+	}
+}
+func (s *syntaxScanner) scanHereStringHeader() error {
+	column, line := s.column, s.line
+	quote := s.peek(1)
+
+	for s.advance(2); (' ' == s.peek(0)) || ('\t' == s.peek(0)) || ('\r' == s.peek(0)); {
+		s.advance(1)
+	}
+
+	if '\n' != s.peek(0) {
+		return s.errorAt(line, column, "No characters are allowed after a here-string header but before the end of the line.")
+	}
+
+	s.advance(1)
+
+	if '"' == quote {
+		s.stack = append(s.stack, syntaxFrame{column: column, kind: `@"`, line: line})
+
+		return nil
+	}
+
+	for ; s.index < len(s.runes); s.advance(1) {
+		if s.isLineStart() && ('\'' == s.peek(0)) && ('@' == s.peek(1)) {
+			s.advance(2)
+
+			return nil
+		}
+	}
+
+	return s.errorAt(line, column, "The string is missing the terminator: '@.")
+}
+func (s *syntaxScanner) scanVerbatimString() error {
+	column, line := s.column, s.line
+
+	for s.advance(1); s.index < len(s.runes); s.advance(1) {
+		if ('\'' == s.peek(0)) && ('\'' == s.peek(1)) {
+			s.advance(1)
+		} else if '\'' == s.peek(0) {
+			s.advance(1)
+
+			return nil
+		}
+	}
+
+	return s.errorAt(line, column, "The string is missing the terminator: '.")
+}
+func (s *syntaxScanner) top() string {
+	if 0 < len(s.stack) {
+		return s.stack[len(s.stack)-1].kind
+	}
+
+	return ""
+}
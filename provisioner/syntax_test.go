@@ -0,0 +1,42 @@
+package pwsh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPwshSyntax(t *testing.T) {
+	testCases := []struct {
+		name            string
+		content         string
+		expectedMessage string
+	}{
+		{name: "balanced", content: "if ($true) { Write-Output @('a', \"b$($c[0])\"); }\n"},
+		{name: "escaped quotes", content: "Write-Output 'it''s'; Write-Output \"say \"\"hi\"\" `\"there`\"\";\n"},
+		{name: "comments", content: "# unbalanced } in a comment\n<# and ( in a\nblock comment #>\nWrite-Output a#b;\n"},
+		{name: "braced variable", content: "${weird name} = 1;\n"},
+		{name: "here-strings", content: "$a = @\"\nbrace { $(Get-Date) \"\n\"@;\n$b = @'\nquote ' and }\n'@;\n"},
+		{name: "generic type", content: "$list = [Collections.Generic.List[string]]::new();\n"},
+		{name: "unclosed brace", content: "if ($true) {\n    Write-Output 'Hello, world!';\n", expectedMessage: "test.ps1:1:12: Missing closing '}'."},
+		{name: "unexpected closer", content: "Write-Output (1 + 2));\n", expectedMessage: "test.ps1:1:21: Unexpected token ')'."},
+		{name: "mismatched closer", content: "$a = @(1, 2};\n", expectedMessage: "test.ps1:1:12: Unexpected token '}'."},
+		{name: "unterminated string", content: "Write-Output 'Hello;\n", expectedMessage: "test.ps1:1:14: The string is missing the terminator: '."},
+		{name: "unterminated expandable string", content: "Write-Output \"Hello;\n", expectedMessage: "test.ps1:1:14: The string is missing the terminator: \"."},
+		{name: "unterminated subexpression", content: "Write-Output \"$(Get-Date\";\n", expectedMessage: "test.ps1:1:25: The string is missing the terminator: \"."},
+		{name: "unterminated here-string", content: "$a = @\"\ntext\n \"@\n", expectedMessage: "test.ps1:1:6: The string is missing the terminator: \"@."},
+		{name: "here-string header", content: "$a = @' text\n'@\n", expectedMessage: "test.ps1:1:6: No characters are allowed after a here-string header"},
+		{name: "unterminated block comment", content: "<# comment\n", expectedMessage: "test.ps1:1:1: The block comment is missing the terminator: #>."},
+	}
+
+	for _, testCase := range testCases {
+		e := checkPwshSyntax("test.ps1", testCase.content)
+
+		if "" == testCase.expectedMessage {
+			if nil != e {
+				t.Errorf("%s: unexpected error: %s", testCase.name, e)
+			}
+		} else if (nil == e) || !strings.HasPrefix(e.Error(), testCase.expectedMessage) {
+			t.Errorf("%s: expected %q, got %v", testCase.name, testCase.expectedMessage, e)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+packer {
+  required_plugins {
+    docker = {
+      source  = "github.com/hashicorp/docker"
+      version = ">= 1.0.0"
+    }
+  }
+}
+
+variable "pwsh_mirror_url" {
+  description = "An apt repository (flat layout) that serves the powershell package."
+  type        = string
+}
+
+source "docker" "ubuntu" {
+  discard = true
+  image   = "ubuntu:22.04"
+}
+
+build {
+  sources = ["source.docker.ubuntu"]
+
+  provisioner "pwsh" {
+    inline = [
+      "Write-Output 'packer-acc-autoupdate';",
+    ]
+    os_type                    = "ubuntu"
+    pwsh_autoupdate_command    = <<-EOT
+      #!/bin/sh
+      set -e
+      echo "deb [trusted=yes] ${var.pwsh_mirror_url} ./" > /etc/apt/sources.list.d/pwsh-mirror.list
+      apt-get update
+      apt-get install -y powershell
+    EOT
+    pwsh_autoupdate_is_enabled = true
+  }
+}
@@ -0,0 +1,20 @@
+source "null" "pwsh" {
+  communicator = "none"
+}
+
+build {
+  sources = ["source.null.pwsh"]
+
+  provisioner "pwsh" {
+    env = {
+      PACKER_ACC_QUOTE = "it's quoted"
+    }
+    environment_vars = [
+      "PACKER_ACC_GREETING=hello world",
+    ]
+    execution_target = "local"
+    inline = [
+      "Write-Output ('packer-acc-env:{0}|{1}|{2}' -f $env:PACKER_ACC_GREETING, $env:PACKER_ACC_QUOTE, $env:PACKER_BUILD_NAME);",
+    ]
+  }
+}
@@ -0,0 +1,16 @@
+source "null" "pwsh" {
+  communicator = "none"
+}
+
+build {
+  sources = ["source.null.pwsh"]
+
+  provisioner "pwsh" {
+    execution_target = "local"
+    inline = [
+      "Write-Output 'packer-acc-exit-code';",
+      "exit 3;",
+    ]
+    valid_exit_codes = [0, 3]
+  }
+}
@@ -0,0 +1,14 @@
+source "null" "pwsh" {
+  communicator = "none"
+}
+
+build {
+  sources = ["source.null.pwsh"]
+
+  provisioner "pwsh" {
+    execution_target = "local"
+    inline = [
+      "Write-Output 'packer-acc-inline';",
+    ]
+  }
+}
@@ -0,0 +1,15 @@
+source "null" "pwsh" {
+  communicator = "none"
+}
+
+build {
+  sources = ["source.null.pwsh"]
+
+  provisioner "pwsh" {
+    execution_target = "local"
+    scripts = [
+      "test-fixtures/scripts/first.ps1",
+      "test-fixtures/scripts/second.ps1",
+    ]
+  }
+}
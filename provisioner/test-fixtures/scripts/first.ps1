@@ -0,0 +1 @@
+Write-Output 'packer-acc-script:first';
@@ -0,0 +1,82 @@
+package pwsh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	_ "embed"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+//go:embed transcript.ps1
+var transcriptTemplatePs1 string
+var transcriptTemplate = template.Must(template.New("Transcript").Parse(transcriptTemplatePs1))
+
+func (p *Provisioner) downloadTranscript(index int, scriptPath string, ui packersdk.Ui) error {
+	if transcriptFileHandle, e := p.createScriptLogFile(p.config.TranscriptDir, index, scriptPath, "transcript.txt"); nil != e {
+		return e
+	} else {
+		defer transcriptFileHandle.Close()
+
+		ui.Say(fmt.Sprintf("Downloading PowerShell transcript; local path: %s", transcriptFileHandle.Name()))
+
+		var buffer bytes.Buffer
+
+		if e = p.communicator.Download(p.config.RemoteTranscriptPath, &buffer); nil != e {
+			return fmt.Errorf(pwshTranscriptDownloadingErrorFormat, e)
+		} else if _, e = transcriptFileHandle.WriteString(packersdk.LogSecretFilter.FilterString(buffer.String())); nil != e {
+			return fmt.Errorf(pwshTranscriptDownloadingErrorFormat, e)
+		}
+
+		return nil
+	}
+}
+func (p *Provisioner) uploadAndExecuteTranscribedScript(ctx context.Context, index int, remotePath string, scriptPath string, uploadScriptPath string, timeout time.Duration, ui packersdk.Ui) (int, error) {
+	var transcribedRemotePath string
+
+	if os.IsPathSeparator(remotePath[len(remotePath)-1]) {
+		transcribedRemotePath = (remotePath + "transcribed-" + filepath.Base(scriptPath))
+	} else {
+		transcribedRemotePath = (strings.TrimSuffix(remotePath, filepath.Ext(remotePath)) + "-transcribed.ps1")
+	}
+
+	if scriptContent, scriptFileInfo, e := p.readScript(uploadScriptPath); nil != e {
+		return -1, e
+	} else if e = p.communicator.Upload(transcribedRemotePath, bytes.NewReader(scriptContent), &scriptFileInfo); nil != e {
+		return -1, fmt.Errorf(pwshScriptUploadingErrorFormat, e)
+	}
+
+	defer func() {
+		if e := p.removeRemoteFiles(ui, p.config.RemoteTranscriptPath, transcribedRemotePath); nil != e {
+			ui.Error(fmt.Sprintf("Failed to remove PowerShell transcript: %s", e))
+		}
+	}()
+
+	var buffer bytes.Buffer
+
+	if e := transcriptTemplate.Execute(&buffer, map[string]string{
+		"ScriptPath":     strings.ReplaceAll(transcribedRemotePath, "'", "''"),
+		"TranscriptPath": strings.ReplaceAll(p.config.RemoteTranscriptPath, "'", "''"),
+	}); nil != e {
+		return -1, fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	} else if wrapperScriptPath, e := p.getInlineScriptFilePath([]string{buffer.String()}); nil != e {
+		return -1, e
+	} else {
+		p.remoteScriptPaths[transcribedRemotePath] = scriptPath
+
+		exitCode, e := p.uploadAndExecuteNamedScript(ctx, remotePath, wrapperScriptPath, scriptPath, timeout, ui)
+
+		if err := p.downloadTranscript(index, scriptPath, ui); nil != err {
+			ui.Error(err.Error())
+		}
+
+		return exitCode, e
+	}
+}
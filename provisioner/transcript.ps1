@@ -0,0 +1,9 @@
+Start-Transcript -Force -IncludeInvocationHeader -Path '{{.TranscriptPath}}' | Out-Null;
+
+try {
+    & '{{.ScriptPath}}';
+    exit $LastExitCode;
+}
+finally {
+    Stop-Transcript | Out-Null;
+}
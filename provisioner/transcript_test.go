@@ -0,0 +1,62 @@
+package pwsh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestProvisioner_ExecuteScriptCollectionWithTranscript(t *testing.T) {
+	testCases := []struct {
+		name            string
+		exitCode        int
+		isErrorExpected bool
+	}{
+		{name: "success"},
+		{name: "failure", exitCode: 1, isErrorExpected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			transcriptDir := t.TempDir()
+			p, communicator := testProvisioner(t, map[string]interface{}{
+				"inline":         []string{"Write-Output 'Hello, world!';"},
+				"os_type":        "ubuntu",
+				"transcript_dir": transcriptDir,
+			}, &fakeCommandRule{command: "chmod +x", responses: []fakeCommandResponse{{exitCode: testCase.exitCode}}})
+			communicator.files[p.config.RemoteTranscriptPath] = "PS> Write-Output 'Hello, world!'\n"
+
+			scriptPaths, e := p.initializeScriptCollection()
+
+			if nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			}
+
+			if e = p.executeScriptCollection(context.Background(), scriptPaths, packersdk.TestUi(t)); testCase.isErrorExpected != (nil != e) {
+				t.Fatalf("expected error: %t, got %v", testCase.isErrorExpected, e)
+			}
+
+			if transcriptPaths, e := filepath.Glob(filepath.Join(transcriptDir, "*.transcript.txt")); (nil != e) || (1 != len(transcriptPaths)) {
+				t.Fatalf("expected exactly one transcript, got %v (%v)", transcriptPaths, e)
+			} else if content, e := os.ReadFile(transcriptPaths[0]); nil != e {
+				t.Fatalf("unexpected error: %s", e)
+			} else if "PS> Write-Output 'Hello, world!'\n" != string(content) {
+				t.Errorf("expected the transcript to be downloaded, got %q", string(content))
+			}
+
+			transcribedRemotePath := (strings.TrimSuffix(p.config.RemotePath, filepath.Ext(p.config.RemotePath)) + "-transcribed.ps1")
+
+			if _, ok := communicator.uploads[transcribedRemotePath]; !ok {
+				t.Fatalf("expected the script to be uploaded to %s", transcribedRemotePath)
+			}
+
+			if removals := communicator.Commands("rm -f " + p.config.RemoteTranscriptPath + " " + transcribedRemotePath); 1 != len(removals) {
+				t.Errorf("expected the remote transcript and script to be removed once, got %q", communicator.commands)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package pwsh
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type captureUi struct {
+	packersdk.Ui
+
+	collector *lineCollector
+}
+type logUi struct {
+	packersdk.Ui
+
+	mutex  sync.Mutex
+	writer io.Writer
+}
+type redactUi struct {
+	packersdk.Ui
+}
+
+func newCaptureUi(ui packersdk.Ui, prefix string) *captureUi {
+	return &captureUi{
+		Ui:        ui,
+		collector: newLineCollector(prefix),
+	}
+}
+func newLogUi(ui packersdk.Ui, writer io.Writer) *logUi {
+	return &logUi{
+		Ui:     ui,
+		writer: writer,
+	}
+}
+func newRedactUi(ui packersdk.Ui) *redactUi {
+	return &redactUi{
+		Ui: ui,
+	}
+}
+
+func (u *captureUi) Lines() []string {
+	return u.collector.Flush()
+}
+func (u *captureUi) Message(message string) {
+	if strings.HasPrefix(message, u.collector.prefix) {
+		u.collector.Write([]byte(message + "\n"))
+	} else {
+		u.Ui.Message(message)
+	}
+}
+func (u *logUi) Error(message string) {
+	u.writeLine("stderr", message)
+	u.Ui.Error(message)
+}
+func (u *logUi) Message(message string) {
+	u.writeLine("stdout", message)
+	u.Ui.Message(message)
+}
+func (u *logUi) Say(message string) {
+	u.writeLine("packer", message)
+	u.Ui.Say(message)
+}
+func (u *redactUi) Error(message string) {
+	u.Ui.Error(packersdk.LogSecretFilter.FilterString(message))
+}
+func (u *redactUi) Machine(category string, args ...string) {
+	for i, arg := range args {
+		args[i] = packersdk.LogSecretFilter.FilterString(arg)
+	}
+
+	u.Ui.Machine(category, args...)
+}
+func (u *redactUi) Message(message string) {
+	u.Ui.Message(packersdk.LogSecretFilter.FilterString(message))
+}
+func (u *redactUi) Say(message string) {
+	u.Ui.Say(packersdk.LogSecretFilter.FilterString(message))
+}
+
+func (u *logUi) writeLine(stream string, message string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	fmt.Fprintf(u.writer, "[%s] %s\n", stream, packersdk.LogSecretFilter.FilterString(message))
+}
@@ -0,0 +1,97 @@
+package pwsh
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	_ "embed"
+
+	"github.com/hashicorp/packer-plugin-sdk/uuid"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+//go:embed windows.elevatedrunner.ps1
+var windowsElevatedRunnerTemplatePs1 string
+var windowsElevatedRunnerTemplate = template.Must(template.New("WindowsElevatedRunner").Parse(windowsElevatedRunnerTemplatePs1))
+
+// The scheduled task must start within this window; otherwise a task that is never scheduled would be waited on forever.
+const windowsElevatedRunnerStartTimeout = (5 * time.Minute)
+
+type windowsElevatedRunner struct {
+	credentialPath string
+	errorLogPath   string
+	outputLogPath  string
+	runnerPath     string
+	taskName       string
+}
+
+func newWindowsElevatedRunner(directory string) *windowsElevatedRunner {
+	taskName := fmt.Sprintf("packer-pwsh-elevated-%s", uuid.TimeOrderedUUID())
+
+	return &windowsElevatedRunner{
+		credentialPath: fmt.Sprintf("%s/%s-credential.txt", directory, taskName),
+		errorLogPath:   fmt.Sprintf("%s/%s-stderr.txt", directory, taskName),
+		outputLogPath:  fmt.Sprintf("%s/%s-stdout.txt", directory, taskName),
+		runnerPath:     fmt.Sprintf("%s/%s.ps1", directory, taskName),
+		taskName:       taskName,
+	}
+}
+
+func (r *windowsElevatedRunner) command() string {
+	return fmt.Sprintf(`powershell -ExecutionPolicy Bypass -NoLogo -NonInteractive -NoProfile -File "%s"`, r.runnerPath)
+}
+func (r *windowsElevatedRunner) removeCommand() string {
+	return fmt.Sprintf(
+		`powershell -NoLogo -NonInteractive -NoProfile -Command "schtasks.exe /End /TN '%[1]s' 2>&1 | Out-Null; schtasks.exe /Delete /F /TN '%[1]s' 2>&1 | Out-Null; Remove-Item -ErrorAction SilentlyContinue -Force -Path '%[2]s', '%[3]s', '%[4]s', '%[5]s';"`,
+		r.taskName,
+		r.credentialPath,
+		r.errorLogPath,
+		r.outputLogPath,
+		r.runnerPath,
+	)
+}
+
+func (p *Provisioner) removeWindowsElevatedRunner(runner *windowsElevatedRunner, ui packersdk.Ui) error {
+	ui.Say(fmt.Sprintf("Removing elevated scheduled task; name: %s", runner.taskName))
+
+	return p.runCleanupCommand(runner.removeCommand(), ui)
+}
+func (p *Provisioner) uploadWindowsElevatedRunner(command string, timeout time.Duration, ui packersdk.Ui) (*windowsElevatedRunner, error) {
+	var buffer bytes.Buffer
+
+	runner := newWindowsElevatedRunner(path.Dir(p.config.RemotePath))
+
+	if e := windowsElevatedRunnerTemplate.Execute(&buffer, map[string]string{
+		"Command":            strings.ReplaceAll(command, "'", "''"),
+		"CredentialPath":     runner.credentialPath,
+		"ErrorLogPath":       runner.errorLogPath,
+		"ExecutionTimeLimit": fmt.Sprintf("PT%dS", int64(timeout.Seconds())),
+		"OutputLogPath":      runner.outputLogPath,
+		"RunnerPath":         runner.runnerPath,
+		"StartTimeout":       fmt.Sprint(int64(windowsElevatedRunnerStartTimeout.Seconds())),
+		"TaskName":           runner.taskName,
+		"UserName":           strings.ReplaceAll(p.ElevatedUser(), "'", "''"),
+	}); nil != e {
+		return nil, fmt.Errorf(pwshScriptPreparingErrorFormat, e)
+	} else if e = p.communicator.Upload(runner.runnerPath, &buffer, nil); nil != e {
+		return nil, fmt.Errorf(pwshScriptUploadingErrorFormat, e)
+	}
+
+	// The password is kept out of the runner script; the runner deletes this file as soon as it has read it.
+	if "" != p.ElevatedPassword() {
+		if e := p.communicator.Upload(runner.credentialPath, strings.NewReader(p.ElevatedPassword()), nil); nil != e {
+			if err := p.removeWindowsElevatedRunner(runner, ui); nil != err {
+				ui.Error(fmt.Sprintf("Failed to remove elevated scheduled task: %s", err))
+			}
+
+			return nil, fmt.Errorf(pwshScriptUploadingErrorFormat, e)
+		}
+	}
+
+	return runner, nil
+}
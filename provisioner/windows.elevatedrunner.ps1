@@ -0,0 +1,101 @@
+$ErrorActionPreference = 'Stop';
+$ProgressPreference = 'SilentlyContinue';
+
+$credentialPath = '{{.CredentialPath}}';
+$errorLogPath = '{{.ErrorLogPath}}';
+$exitCode = 1;
+$logReaders = [Collections.Generic.List[object]]::new();
+$outputLogPath = '{{.OutputLogPath}}';
+$password = $null;
+$runnerPath = '{{.RunnerPath}}';
+$startTimeout = [timespan]::FromSeconds({{.StartTimeout}});
+$taskName = '{{.TaskName}}';
+$userName = '{{.UserName}}';
+
+if (Test-Path -PathType Leaf -Path $credentialPath) {
+    try {
+        $password = [IO.File]::ReadAllText($credentialPath);
+    }
+    finally {
+        Remove-Item -Force -Path $credentialPath;
+    }
+}
+
+function Read-LogFiles {
+    foreach ($logPath in @($outputLogPath, $errorLogPath)) {
+        $logReader = $logReaders | Where-Object { $_.Path -eq $logPath; };
+
+        if ((-not $logReader) -and (Test-Path -PathType Leaf -Path $logPath)) {
+            $logReader = [pscustomobject]@{
+                Path = $logPath;
+                Reader = [IO.StreamReader]::new([IO.FileStream]::new($logPath, [IO.FileMode]::Open, [IO.FileAccess]::Read, ([IO.FileShare]::Delete -bor [IO.FileShare]::ReadWrite)));
+                Writer = $(if ($logPath -eq $errorLogPath) { [Console]::Error; } else { [Console]::Out; });
+            };
+            $logReaders.Add($logReader);
+        }
+
+        if ($logReader) {
+            $text = $logReader.Reader.ReadToEnd();
+
+            if ($text) {
+                $logReader.Writer.Write($text);
+                $logReader.Writer.Flush();
+            }
+        }
+    }
+}
+
+$scheduleService = New-Object -ComObject 'Schedule.Service';
+$scheduleService.Connect();
+$taskFolder = $scheduleService.GetFolder('\');
+
+try {
+    $taskDefinition = $scheduleService.NewTask(0);
+    $taskDefinition.Principal.RunLevel = 1;
+    $taskDefinition.RegistrationInfo.Description = 'Packer PowerShell elevated task';
+    $taskDefinition.Settings.DisallowStartIfOnBatteries = $false;
+    $taskDefinition.Settings.ExecutionTimeLimit = '{{.ExecutionTimeLimit}}';
+    $taskDefinition.Settings.MultipleInstances = 2;
+    $taskDefinition.Settings.StopIfGoingOnBatteries = $false;
+
+    $taskAction = $taskDefinition.Actions.Create(0);
+    $taskAction.Arguments = ('/s /c "{0} 1>"{1}" 2>"{2}""' -f '{{.Command}}', $outputLogPath, $errorLogPath);
+    $taskAction.Path = 'cmd.exe';
+
+    if ($password) {
+        $task = $taskFolder.RegisterTaskDefinition($taskName, $taskDefinition, 6, $userName, $password, 1, $null);
+    }
+    else {
+        $task = $taskFolder.RegisterTaskDefinition($taskName, $taskDefinition, 6, $userName, $null, 5, $null);
+    }
+
+    Remove-Variable -Name password;
+    $task.Run($null) | Out-Null;
+    $stopwatch = [Diagnostics.Stopwatch]::StartNew();
+
+    # 267009, 267011 and 267045 are SCHED_S_TASK_RUNNING, SCHED_S_TASK_HAS_NOT_RUN and SCHED_S_TASK_QUEUED.
+    while ((3 -ne $task.State) -or (@(267009, 267011, 267045) -contains $task.LastTaskResult)) {
+        if ((@(267011, 267045) -contains $task.LastTaskResult) -and ($startTimeout -lt $stopwatch.Elapsed)) {
+            throw ('The elevated scheduled task did not start within {0}.' -f $startTimeout);
+        }
+
+        Read-LogFiles;
+        Start-Sleep -Milliseconds 100;
+    }
+
+    Read-LogFiles;
+    $exitCode = $task.LastTaskResult;
+}
+finally {
+    foreach ($logReader in $logReaders) {
+        $logReader.Reader.Dispose();
+    }
+
+    try { $taskFolder.GetTask($taskName).Stop(0); } catch { }
+    try { $taskFolder.DeleteTask($taskName, 0); } catch { }
+
+    Remove-Item -ErrorAction SilentlyContinue -Force -Path @($credentialPath, $errorLogPath, $outputLogPath, $runnerPath);
+    [Runtime.InteropServices.Marshal]::ReleaseComObject($scheduleService) | Out-Null;
+}
+
+exit $exitCode;
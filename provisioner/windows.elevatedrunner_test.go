@@ -0,0 +1,46 @@
+package pwsh
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestProvisioner_UploadWindowsElevatedRunner(t *testing.T) {
+	p, communicator := testProvisioner(t, map[string]interface{}{
+		"elevated_password": "it's secret",
+		"elevated_user":     "packer",
+		"inline":            []string{"Write-Output 'Hello, world!';"},
+		"os_type":           "windows",
+		"remote_path":       "D:/Packer/script.ps1",
+	})
+
+	runner, e := p.uploadWindowsElevatedRunner("pwsh -File 'D:/Packer/script.ps1'", (30 * time.Second), packersdk.TestUi(t))
+
+	if nil != e {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	for _, remotePath := range []string{runner.credentialPath, runner.errorLogPath, runner.outputLogPath, runner.runnerPath} {
+		if "D:/Packer" != path.Dir(remotePath) {
+			t.Errorf("expected %q to be placed next to the remote script", remotePath)
+		}
+	}
+
+	if script := communicator.uploads[runner.runnerPath]; strings.Contains(script, "secret") {
+		t.Errorf("expected the runner script to not contain the password, got %q", script)
+	} else if !strings.Contains(script, "'PT30S'") || !strings.Contains(script, "FromSeconds(300)") {
+		t.Errorf("expected the runner script to carry the execution and start timeouts, got %q", script)
+	}
+
+	if credential := communicator.uploads[runner.credentialPath]; "it's secret" != credential {
+		t.Errorf("expected the password to be uploaded separately, got %q", credential)
+	}
+
+	if removeCommand := runner.removeCommand(); !strings.Contains(removeCommand, runner.credentialPath) {
+		t.Errorf("expected the remove command to delete the credential file, got %q", removeCommand)
+	}
+}
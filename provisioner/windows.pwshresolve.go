@@ -0,0 +1,11 @@
+package pwsh
+
+import (
+	"text/template"
+
+	_ "embed"
+)
+
+//go:embed windows.pwshresolve.ps1
+var windowsPwshResolveTemplatePs1 string
+var windowsPwshResolveTemplate = template.Must(template.New("WindowsPwshResolve").Parse(windowsPwshResolveTemplatePs1))
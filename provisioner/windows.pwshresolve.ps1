@@ -0,0 +1,26 @@
+$candidatePaths = [Collections.Generic.List[string]]::new();
+$edition = '{{.Edition}}';
+$pwshPath = '{{.PwshPath}}';
+
+if ($pwshPath) {
+    $candidatePaths.Add($pwshPath);
+}
+else {
+    if ('desktop' -ne $edition) {
+        Get-ChildItem -ErrorAction SilentlyContinue -Filter 'pwsh.exe' -Path (Join-Path -ChildPath 'PowerShell' -Path $env:ProgramFiles) -Recurse | ForEach-Object { $candidatePaths.Add($_.FullName); };
+        Get-Command -CommandType Application -ErrorAction SilentlyContinue -Name 'pwsh.exe' | ForEach-Object { $candidatePaths.Add($_.Source); };
+    }
+
+    if ('core' -ne $edition) {
+        Get-Command -CommandType Application -ErrorAction SilentlyContinue -Name 'powershell.exe' | ForEach-Object { $candidatePaths.Add($_.Source); };
+    }
+}
+
+foreach ($candidatePath in $candidatePaths) {
+    if (Test-Path -PathType Leaf -Path $candidatePath) {
+        & $candidatePath -NoLogo -NonInteractive -NoProfile -Command '''{{.Prefix}}{0}|{1}|{2}'' -f (Get-Process -Id $PID).Path, $PSVersionTable.PSEdition, $PSVersionTable.PSVersion.ToString()';
+        exit $LastExitCode;
+    }
+}
+
+exit 1;